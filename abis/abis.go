@@ -0,0 +1,34 @@
+// Package abis holds the ABIs of the contracts that are shared by the other packages of the SDK
+package abis
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+//go:embed contracts/interfaces/IPool.sol/IPool.json
+var poolABI []byte
+
+// PoolABI is the ABI of the ProMM pool, it is parsed once as the logs of pools are decoded one by one while syncing
+var PoolABI = GetABI(poolABI)
+
+// WrappedABI is the JSON artifact of a contract, which holds its ABI
+type WrappedABI struct {
+	ABI abi.ABI `json:"abi"`
+}
+
+/**
+ * Parses the ABI of a contract artifact, it panics on invalid artifacts as they are embedded at build time
+ * @param abi The JSON artifact of the contract
+ * @returns The ABI of the contract
+ */
+func GetABI(abi []byte) abi.ABI {
+	var wabi WrappedABI
+	err := json.Unmarshal(abi, &wabi)
+	if err != nil {
+		panic(err)
+	}
+	return wabi.ABI
+}
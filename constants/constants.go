@@ -46,6 +46,9 @@ var TickSpacings = map[FeeAmount]int{
 	Fee5:    100,
 }
 
+// The default maximum number of ticks a single step of a swap can travel, the MAX_TICK_DISTANCE of the pool contract.
+const MaxTickDistance = 480
+
 var (
 	NegativeOne = big.NewInt(-1)
 	Zero        = big.NewInt(0)
@@ -0,0 +1,110 @@
+package constants
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrUnknownChain    = errors.New("unknown chain")
+	ErrUnknownContract = errors.New("contract not known on the chain")
+)
+
+// Deployment is the set of contracts of the protocol on a chain. Zero addresses are contracts not deployed, or not
+// known, on the chain, the call parameters targeting them are returned without a target
+type Deployment struct {
+	Factory                    common.Address
+	PoolInitCodeHash           string // The init code hash of the pools created by the factory
+	Router                     common.Address
+	PositionManager            common.Address // The ProMM base position manager
+	NonfungiblePositionManager common.Address // The position manager with the Uniswap v3 interface
+	Quoter                     common.Address
+	QuoterV2                   common.Address
+	Staker                     common.Address
+	TickReader                 common.Address // The tick lens of the pools
+	Multicall                  common.Address // The UniswapInterfaceMulticall the reads of the pools are batched with
+	WETH                       *entities.Token
+}
+
+// newDeployment returns a deployment of the default factory with the given wrapped native token
+func newDeployment(weth *entities.Token) *Deployment {
+	return &Deployment{
+		Factory:          FactoryAddress,
+		PoolInitCodeHash: PoolInitCodeHash,
+		WETH:             weth,
+	}
+}
+
+// DeploymentRegistry holds the deployments of the protocol by chain ID, it is safe for concurrent use
+type DeploymentRegistry struct {
+	mu          sync.RWMutex
+	deployments map[uint]Deployment
+}
+
+/**
+ * Constructs a deployment registry
+ * @param deployments The deployments by chain ID, they are copied
+ */
+func NewDeploymentRegistry(deployments map[uint]*Deployment) *DeploymentRegistry {
+	r := &DeploymentRegistry{deployments: make(map[uint]Deployment, len(deployments))}
+	for chainID, deployment := range deployments {
+		r.deployments[chainID] = *deployment
+	}
+	return r
+}
+
+/**
+ * Registers the deployment of a chain, replacing the previous one
+ * @param chainID The chain ID
+ * @param deployment The deployment, it is copied
+ */
+func (r *DeploymentRegistry) Register(chainID uint, deployment *Deployment) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deployments[chainID] = *deployment
+}
+
+/**
+ * Returns the deployment of a chain
+ * @param chainID The chain ID
+ * @returns A copy of the registered deployment, ErrUnknownChain if there is none
+ */
+func (r *DeploymentRegistry) Get(chainID uint) (*Deployment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deployment, ok := r.deployments[chainID]
+	if !ok {
+		return nil, ErrUnknownChain
+	}
+	return &deployment, nil
+}
+
+// The known deployments by chain ID. Deployments can be registered to add chains or override the built-in defaults,
+// which only know the default factory and the wrapped native token of each chain
+var Deployments = NewDeploymentRegistry(map[uint]*Deployment{
+	1:      newDeployment(entities.WETH9[1]),
+	3:      newDeployment(entities.WETH9[3]),
+	4:      newDeployment(entities.WETH9[4]),
+	5:      newDeployment(entities.WETH9[5]),
+	10:     newDeployment(entities.WETH9[10]),
+	42:     newDeployment(entities.WETH9[42]),
+	56:     newDeployment(entities.NewToken(56, common.HexToAddress("0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c"), 18, "WBNB", "Wrapped BNB")),
+	69:     newDeployment(entities.WETH9[69]),
+	137:    newDeployment(entities.NewToken(137, common.HexToAddress("0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270"), 18, "WMATIC", "Wrapped Matic")),
+	250:    newDeployment(entities.NewToken(250, common.HexToAddress("0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83"), 18, "WFTM", "Wrapped Fantom")),
+	42161:  newDeployment(entities.WETH9[42161]),
+	43114:  newDeployment(entities.NewToken(43114, common.HexToAddress("0xB31f66AA3C1e785363F0875A1B74E27b85FD66c7"), 18, "WAVAX", "Wrapped AVAX")),
+	421611: newDeployment(entities.WETH9[421611]),
+})
+
+/**
+ * Returns the deployment of a chain from Deployments
+ * @param chainID The chain ID
+ * @returns A copy of the registered deployment, ErrUnknownChain if there is none
+ */
+func GetDeployment(chainID uint) (*Deployment, error) {
+	return Deployments.Get(chainID)
+}
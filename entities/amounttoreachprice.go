@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+// maxInt256 is the largest amount that can be specified for a swap
+var maxInt256 = new(big.Int).Sub(new(big.Int).Lsh(constants.One, 255), constants.One)
+
+/**
+ * Computes the swap needed to move the price of the pool to the target sqrt price, i.e. how much token0 must be sold
+ * for the price to go down to the target, or how much token1 must be sold for the price to go up to the target.
+ * Swapping the returned input amount moves the price to the target, up to rounding
+ * @param targetSqrtPriceX96 The Q64.96 sqrt price to reach
+ * @returns The swap result, including the input amount needed, the output amount and the fees
+ */
+func (p *Pool) AmountToReachSqrtPrice(targetSqrtPriceX96 *big.Int) (*SwapResult, error) {
+	cmp := targetSqrtPriceX96.Cmp(p.SqrtRatioX96)
+	if cmp == 0 {
+		var rTokenMinted *big.Int
+		if p.ReinvestLiquidityLast != nil && p.RTotalSupply != nil {
+			rTokenMinted = big.NewInt(0)
+		}
+		return &SwapResult{
+			InputAmount:             entities.FromRawAmount(p.Token0, big.NewInt(0)),
+			OutputAmount:            entities.FromRawAmount(p.Token1, big.NewInt(0)),
+			Pool:                    p,
+			FeeAmount:               entities.FromRawAmount(p.Token0, big.NewInt(0)),
+			ReinvestLiquidityMinted: big.NewInt(0),
+			RTokenMinted:            rTokenMinted,
+		}, nil
+	}
+
+	// the price goes down when token0 is sold
+	zeroForOne := cmp < 0
+	inputToken, outputToken := p.Token0, p.Token1
+	if !zeroForOne {
+		inputToken, outputToken = p.Token1, p.Token0
+	}
+
+	result, err := p.swap(zeroForOne, maxInt256, targetSqrtPriceX96)
+	if err != nil {
+		return nil, err
+	}
+	inputAmount := entities.FromRawAmount(inputToken, new(big.Int).Sub(maxInt256, result.amountSpecifiedRemaining))
+	outputAmount := entities.FromRawAmount(outputToken, new(big.Int).Neg(result.amountCalculated))
+	if result.exhaustedErr != nil {
+		return nil, &InsufficientLiquidityError{AmountIn: inputAmount, AmountOut: outputAmount, err: result.exhaustedErr}
+	}
+
+	pool, err := p.afterSwap(result)
+	if err != nil {
+		return nil, err
+	}
+	return newSwapResult(inputToken, inputAmount, outputAmount, pool, result), nil
+}
+
+/**
+ * Same as AmountToReachSqrtPrice, with the target given as a price of one pool token in terms of the other
+ * @param price The price to reach, its base and quote currencies must be the tokens of the pool
+ * @returns The swap result, including the input amount needed, the output amount and the fees
+ */
+func (p *Pool) AmountToReachPrice(price *entities.Price) (*SwapResult, error) {
+	if !price.BaseCurrency.IsToken() || !price.QuoteCurrency.IsToken() {
+		return nil, ErrTokenNotInvolved
+	}
+	baseToken, quoteToken := price.BaseCurrency.Wrapped(), price.QuoteCurrency.Wrapped()
+	var targetSqrtPriceX96 *big.Int
+	switch {
+	case baseToken.Equal(p.Token0) && quoteToken.Equal(p.Token1):
+		targetSqrtPriceX96 = utils.EncodeSqrtRatioX96(price.Numerator, price.Denominator)
+	case baseToken.Equal(p.Token1) && quoteToken.Equal(p.Token0):
+		targetSqrtPriceX96 = utils.EncodeSqrtRatioX96(price.Denominator, price.Numerator)
+	default:
+		return nil, ErrTokenNotInvolved
+	}
+	return p.AmountToReachSqrtPrice(targetSqrtPriceX96)
+}
@@ -0,0 +1,77 @@
+package entities
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+func TestPool_AmountToReachSqrtPrice(t *testing.T) {
+	pool := newCrossTickTestPool(t)
+
+	// the price goes up through initialized ticks, token1 (USDC) is sold
+	target, err := utils.GetSqrtRatioAtTick(250)
+	assert.NoError(t, err)
+	result, err := pool.AmountToReachSqrtPrice(target)
+	assert.NoError(t, err)
+	assert.True(t, result.InputAmount.Currency.Equal(USDC))
+	assert.True(t, result.OutputAmount.Currency.Equal(DAI))
+	assert.Equal(t, target, result.Pool.SqrtRatioX96)
+	assert.Equal(t, 250, result.Pool.TickCurrent)
+	assert.True(t, result.FeeAmount.Quotient().Sign() > 0)
+
+	// swapping the input amount moves the price to the target, up to rounding
+	outputAmount, newPool, err := pool.GetOutputAmount(result.InputAmount, nil)
+	assert.NoError(t, err)
+	diff := new(big.Int).Sub(result.OutputAmount.Quotient(), outputAmount.Quotient())
+	assert.True(t, diff.CmpAbs(big.NewInt(10)) <= 0)
+	assert.True(t, newPool.TickCurrent == 249 || newPool.TickCurrent == 250)
+
+	// the price goes down, token0 (DAI) is sold
+	target, err = utils.GetSqrtRatioAtTick(-50)
+	assert.NoError(t, err)
+	result, err = pool.AmountToReachSqrtPrice(target)
+	assert.NoError(t, err)
+	assert.True(t, result.InputAmount.Currency.Equal(DAI))
+	assert.Equal(t, target, result.Pool.SqrtRatioX96)
+
+	// already at the target
+	result, err = pool.AmountToReachSqrtPrice(pool.SqrtRatioX96)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.InputAmount.Quotient().Sign())
+	assert.Equal(t, pool, result.Pool)
+
+	// beyond the initialized ticks
+	target, err = utils.GetSqrtRatioAtTick(-200)
+	assert.NoError(t, err)
+	_, err = pool.AmountToReachSqrtPrice(target)
+	var liquidityErr *InsufficientLiquidityError
+	assert.ErrorAs(t, err, &liquidityErr)
+}
+
+func TestPool_AmountToReachPrice(t *testing.T) {
+	pool := newCrossTickTestPool(t)
+
+	targetSqrtPriceX96, err := utils.GetSqrtRatioAtTick(150)
+	assert.NoError(t, err)
+	expected, err := pool.AmountToReachSqrtPrice(targetSqrtPriceX96)
+	assert.NoError(t, err)
+
+	price, err := utils.TickToPrice(DAI, USDC, 150)
+	assert.NoError(t, err)
+	result, err := pool.AmountToReachPrice(price)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.InputAmount.Quotient(), result.InputAmount.Quotient())
+
+	// the same price, quoted the other way around
+	result, err = pool.AmountToReachPrice(price.Invert())
+	assert.NoError(t, err)
+	assert.Equal(t, expected.InputAmount.Quotient(), result.InputAmount.Quotient())
+
+	_, err = pool.AmountToReachPrice(entities.NewPrice(DAI, entities.WETH9[1], big.NewInt(1), big.NewInt(1)))
+	assert.ErrorIs(t, err, ErrTokenNotInvolved)
+}
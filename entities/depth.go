@@ -0,0 +1,204 @@
+package entities
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+var (
+	ErrInvalidDepthPercent = errors.New("invalid depth percent")
+	ErrInvalidTickRange    = errors.New("invalid tick range")
+)
+
+// PoolDepth is the amount of each token that can be traded before the price of the pool moves by a percentage
+type PoolDepth struct {
+	Percent *entities.Percent // The price move the depth is computed for
+
+	// The amount of token0 that can be sold before the price of token0 goes down by Percent, and the token1 received
+	Token0In  *entities.CurrencyAmount
+	Token1Out *entities.CurrencyAmount
+
+	// The amount of token1 that can be sold before the price of token0 goes up by Percent, and the token0 received
+	Token1In  *entities.CurrencyAmount
+	Token0Out *entities.CurrencyAmount
+}
+
+// LiquidityRange is the base liquidity that is active while the current tick is within [TickLower, TickUpper)
+type LiquidityRange struct {
+	TickLower int
+	TickUpper int
+	Liquidity *big.Int
+}
+
+/**
+ * Computes the depth of the pool, i.e. how much of each token can be traded before the price moves by the given
+ * percentage in either direction. When the initialized ticks run out before the price moves by the percentage,
+ * the amounts that can be traded until then are returned
+ * @param percent The price move, must be greater than 0 and less than 100% so the price stays positive when it goes down
+ * @returns The depth of the pool on both sides of the current price
+ */
+func (p *Pool) Depth(percent *entities.Percent) (*PoolDepth, error) {
+	if !percent.GreaterThan(constants.PercentZero) || !percent.LessThan(entities.NewFraction(constants.One, constants.One)) {
+		return nil, ErrInvalidDepthPercent
+	}
+
+	// the price is the square of the sqrt price, so sqrt(P * (1 ± percent)) = sqrt(sqrtP² * (den ± num) / den)
+	sqrtPriceSquared := new(big.Int).Mul(p.SqrtRatioX96, p.SqrtRatioX96)
+	targetSqrtPriceX96 := func(numerator *big.Int) *big.Int {
+		priceX192 := new(big.Int).Mul(sqrtPriceSquared, numerator)
+		return priceX192.Div(priceX192, percent.Denominator).Sqrt(priceX192)
+	}
+
+	downSqrtPriceX96 := targetSqrtPriceX96(new(big.Int).Sub(percent.Denominator, percent.Numerator))
+	if minimum := new(big.Int).Add(utils.MinSqrtRatio, constants.One); downSqrtPriceX96.Cmp(minimum) < 0 {
+		downSqrtPriceX96 = minimum
+	}
+	token0In, token1Out, err := p.depthAmounts(downSqrtPriceX96, true)
+	if err != nil {
+		return nil, err
+	}
+
+	upSqrtPriceX96 := targetSqrtPriceX96(new(big.Int).Add(percent.Denominator, percent.Numerator))
+	if maximum := new(big.Int).Sub(utils.MaxSqrtRatio, constants.One); upSqrtPriceX96.Cmp(maximum) > 0 {
+		upSqrtPriceX96 = maximum
+	}
+	token1In, token0Out, err := p.depthAmounts(upSqrtPriceX96, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PoolDepth{
+		Percent:   percent,
+		Token0In:  token0In,
+		Token1Out: token1Out,
+		Token1In:  token1In,
+		Token0Out: token0Out,
+	}, nil
+}
+
+// depthAmounts returns the amounts swapped to move the price to the target, or until the initialized ticks run out
+func (p *Pool) depthAmounts(targetSqrtPriceX96 *big.Int, zeroForOne bool) (*entities.CurrencyAmount, *entities.CurrencyAmount, error) {
+	if targetSqrtPriceX96.Cmp(p.SqrtRatioX96) == 0 {
+		// a tiny percent can round the target to the current price, nothing is swapped in the requested direction
+		inputToken, outputToken := p.Token0, p.Token1
+		if !zeroForOne {
+			inputToken, outputToken = p.Token1, p.Token0
+		}
+		return entities.FromRawAmount(inputToken, big.NewInt(0)), entities.FromRawAmount(outputToken, big.NewInt(0)), nil
+	}
+
+	result, err := p.AmountToReachSqrtPrice(targetSqrtPriceX96)
+	var liquidityErr *InsufficientLiquidityError
+	if errors.As(err, &liquidityErr) {
+		return liquidityErr.AmountIn, liquidityErr.AmountOut, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.InputAmount, result.OutputAmount, nil
+}
+
+/**
+ * Returns the base liquidity of the pool over a tick range, split into the ranges delimited by the initialized ticks.
+ * The liquidity is accumulated from the current liquidity using the LiquidityNet of the crossed ticks. The reinvestment
+ * liquidity, which is active over the whole price range, is not included
+ * @param tickFrom The lower end of the range
+ * @param tickTo The upper end of the range, exclusive
+ * @returns The consecutive liquidity ranges covering [tickFrom, tickTo), in ascending order
+ */
+func (p *Pool) LiquidityDistribution(tickFrom, tickTo int) ([]LiquidityRange, error) {
+	if tickFrom >= tickTo || tickFrom < utils.MinTick || tickTo > utils.MaxTick {
+		return nil, ErrInvalidTickRange
+	}
+	if p.TickDataProvider == nil {
+		return []LiquidityRange{{TickLower: tickFrom, TickUpper: tickTo, Liquidity: new(big.Int).Set(p.Liquidity)}}, nil
+	}
+
+	liquidity, err := p.liquidityAtTick(tickFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges []LiquidityRange
+	start, tick := tickFrom, tickFrom
+	for {
+		next, initialized, err := p.TickDataProvider.NextInitializedTickWithinOneWord(tick, false, p.tickSpacing())
+		if errors.Is(err, ErrAtOrAboveLargest) || (err == nil && next >= tickTo) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if initialized {
+			if next > start {
+				ranges = append(ranges, LiquidityRange{TickLower: start, TickUpper: next, Liquidity: liquidity})
+			}
+			liquidityNet, err := p.liquidityNet(next)
+			if err != nil {
+				return nil, err
+			}
+			liquidity = new(big.Int).Add(liquidity, liquidityNet)
+			start = next
+		}
+		tick = next
+	}
+	return append(ranges, LiquidityRange{TickLower: start, TickUpper: tickTo, Liquidity: liquidity}), nil
+}
+
+// liquidityAtTick returns the base liquidity that would be active if the current tick of the pool was the given tick
+func (p *Pool) liquidityAtTick(target int) (*big.Int, error) {
+	liquidity := new(big.Int).Set(p.Liquidity)
+	tick := p.TickCurrent
+	if target <= p.TickCurrent {
+		// cross the initialized ticks in (target, tickCurrent] downwards
+		for {
+			next, initialized, err := p.TickDataProvider.NextInitializedTickWithinOneWord(tick, true, p.tickSpacing())
+			if errors.Is(err, ErrBelowSmallest) || (err == nil && next <= target) {
+				return liquidity, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if initialized {
+				liquidityNet, err := p.liquidityNet(next)
+				if err != nil {
+					return nil, err
+				}
+				liquidity.Sub(liquidity, liquidityNet)
+			}
+			tick = next - 1
+		}
+	}
+
+	// cross the initialized ticks in (tickCurrent, target] upwards
+	for {
+		next, initialized, err := p.TickDataProvider.NextInitializedTickWithinOneWord(tick, false, p.tickSpacing())
+		if errors.Is(err, ErrAtOrAboveLargest) || (err == nil && next > target) {
+			return liquidity, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if initialized {
+			liquidityNet, err := p.liquidityNet(next)
+			if err != nil {
+				return nil, err
+			}
+			liquidity.Add(liquidity, liquidityNet)
+		}
+		tick = next
+	}
+}
+
+func (p *Pool) liquidityNet(index int) (*big.Int, error) {
+	tick, err := p.TickDataProvider.GetTick(index)
+	if err != nil {
+		return nil, err
+	}
+	return tick.LiquidityNet, nil
+}
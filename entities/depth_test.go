@@ -0,0 +1,93 @@
+package entities
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+func TestPool_Depth(t *testing.T) {
+	pool := newCrossTickTestPool(t)
+
+	// a 0.5% move is between 50 and 51 ticks
+	depth, err := pool.Depth(entities.NewPercent(big.NewInt(5), big.NewInt(1000)))
+	assert.NoError(t, err)
+	assert.True(t, depth.Token0In.Currency.Equal(pool.Token0))
+	assert.True(t, depth.Token1Out.Currency.Equal(pool.Token1))
+	assert.True(t, depth.Token1In.Currency.Equal(pool.Token1))
+	assert.True(t, depth.Token0Out.Currency.Equal(pool.Token0))
+
+	amountToTick := func(tick int) *big.Int {
+		sqrtPriceX96, err := utils.GetSqrtRatioAtTick(tick)
+		assert.NoError(t, err)
+		result, err := pool.AmountToReachSqrtPrice(sqrtPriceX96)
+		assert.NoError(t, err)
+		return result.InputAmount.Quotient()
+	}
+	assert.True(t, depth.Token0In.Quotient().Cmp(amountToTick(-50)) > 0)
+	assert.True(t, depth.Token0In.Quotient().Cmp(amountToTick(-51)) < 0)
+	assert.True(t, depth.Token1In.Quotient().Cmp(amountToTick(49)) > 0)
+	assert.True(t, depth.Token1In.Quotient().Cmp(amountToTick(50)) < 0)
+
+	// a 2% move goes beyond the lowest initialized tick, only the amounts until then can be traded
+	depth, err = pool.Depth(entities.NewPercent(big.NewInt(2), big.NewInt(100)))
+	assert.NoError(t, err)
+	assert.Equal(t, amountToTick(-100), depth.Token0In.Quotient())
+	assert.True(t, depth.Token1In.Quotient().Cmp(amountToTick(198)) > 0)
+
+	// a percent so small that the upper target rounds to the current sqrt price
+	depth, err = pool.Depth(entities.NewPercent(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)))
+	assert.NoError(t, err)
+	assert.True(t, depth.Token1In.Currency.Equal(pool.Token1))
+	assert.True(t, depth.Token0Out.Currency.Equal(pool.Token0))
+	assert.Equal(t, 0, depth.Token1In.Quotient().Sign())
+	assert.Equal(t, 0, depth.Token0Out.Quotient().Sign())
+	assert.True(t, depth.Token0In.Currency.Equal(pool.Token0))
+	assert.True(t, depth.Token1Out.Currency.Equal(pool.Token1))
+
+	_, err = pool.Depth(entities.NewPercent(big.NewInt(0), big.NewInt(100)))
+	assert.ErrorIs(t, err, ErrInvalidDepthPercent)
+	_, err = pool.Depth(entities.NewPercent(big.NewInt(100), big.NewInt(100)))
+	assert.ErrorIs(t, err, ErrInvalidDepthPercent)
+}
+
+func TestPool_LiquidityDistribution(t *testing.T) {
+	pool := newCrossTickTestPool(t)
+
+	ranges, err := pool.LiquidityDistribution(-200, 400)
+	assert.NoError(t, err)
+	assert.Equal(t, []LiquidityRange{
+		{TickLower: -200, TickUpper: -100, Liquidity: big.NewInt(0)},
+		{TickLower: -100, TickUpper: 100, Liquidity: OneEther},
+		{TickLower: 100, TickUpper: 200, Liquidity: big.NewInt(0)},
+		{TickLower: 200, TickUpper: 300, Liquidity: OneEther},
+		{TickLower: 300, TickUpper: 400, Liquidity: big.NewInt(0)},
+	}, normalizeRanges(ranges))
+
+	// the range starts at an initialized tick above the current tick
+	ranges, err = pool.LiquidityDistribution(200, 250)
+	assert.NoError(t, err)
+	assert.Equal(t, []LiquidityRange{{TickLower: 200, TickUpper: 250, Liquidity: OneEther}}, normalizeRanges(ranges))
+
+	ranges, err = pool.LiquidityDistribution(50, 150)
+	assert.NoError(t, err)
+	assert.Equal(t, []LiquidityRange{
+		{TickLower: 50, TickUpper: 100, Liquidity: OneEther},
+		{TickLower: 100, TickUpper: 150, Liquidity: big.NewInt(0)},
+	}, normalizeRanges(ranges))
+
+	_, err = pool.LiquidityDistribution(100, 100)
+	assert.ErrorIs(t, err, ErrInvalidTickRange)
+}
+
+// normalizeRanges rebuilds the liquidities so that equal values compare equal regardless of their internal state
+func normalizeRanges(ranges []LiquidityRange) []LiquidityRange {
+	for i := range ranges {
+		ranges[i].Liquidity = new(big.Int).SetBytes(ranges[i].Liquidity.Bytes())
+	}
+	return ranges
+}
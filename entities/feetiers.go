@@ -0,0 +1,77 @@
+package entities
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+)
+
+var (
+	ErrUnknownFeeTier        = errors.New("unknown fee tier")
+	ErrFeeTierAlreadyEnabled = errors.New("fee tier already enabled")
+)
+
+// FeeTierRegistry provides the tick spacing of the fee tiers enabled on a factory
+type FeeTierRegistry interface {
+	/**
+	 * Return the tick spacing of a fee tier
+	 * @param fee The fee of the tier
+	 * @returns The tick spacing, and whether the fee tier is enabled
+	 */
+	TickSpacing(fee constants.FeeAmount) (int, bool)
+}
+
+// FeeTiers is a FeeTierRegistry that can be extended at runtime, it is safe for concurrent use
+type FeeTiers struct {
+	mu           sync.RWMutex
+	tickSpacings map[constants.FeeAmount]int
+}
+
+/**
+ * Constructs a fee tier registry
+ * @param tickSpacings The tick spacing of the enabled fee tiers, the map is copied
+ */
+func NewFeeTiers(tickSpacings map[constants.FeeAmount]int) *FeeTiers {
+	f := &FeeTiers{tickSpacings: make(map[constants.FeeAmount]int, len(tickSpacings))}
+	for fee, tickSpacing := range tickSpacings {
+		f.tickSpacings[fee] = tickSpacing
+	}
+	return f
+}
+
+func (f *FeeTiers) TickSpacing(fee constants.FeeAmount) (int, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	tickSpacing, ok := f.tickSpacings[fee]
+	return tickSpacing, ok
+}
+
+/**
+ * Enables a fee tier, as the factory does when governance enables a new swap fee
+ * @param fee The fee of the tier
+ * @param tickSpacing The tick spacing of the pools of the tier
+ */
+func (f *FeeTiers) Enable(fee constants.FeeAmount, tickSpacing int) error {
+	if fee >= constants.FeeMax {
+		return ErrFeeTooHigh
+	}
+	if tickSpacing <= 0 {
+		return ErrZeroTickSpacing
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.tickSpacings[fee]; ok {
+		if existing != tickSpacing {
+			return ErrFeeTierAlreadyEnabled
+		}
+		return nil
+	}
+	f.tickSpacings[fee] = tickSpacing
+	return nil
+}
+
+// DefaultFeeTiers holds the default fee tiers of constants.TickSpacings, NewPool reads the tick spacing of pools from
+// it. New fee tiers can be enabled on it at runtime, other registries are passed to NewPoolWithFeeTiers
+var DefaultFeeTiers = NewFeeTiers(constants.TickSpacings)
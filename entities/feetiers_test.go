@@ -0,0 +1,88 @@
+package entities
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+type fixedFeeTiers int
+
+func (f fixedFeeTiers) TickSpacing(constants.FeeAmount) (int, bool) {
+	return int(f), true
+}
+
+func TestFeeTiers(t *testing.T) {
+	feeTiers := NewFeeTiers(map[constants.FeeAmount]int{constants.Fee01: 10})
+	tickSpacing, ok := feeTiers.TickSpacing(constants.Fee01)
+	assert.True(t, ok)
+	assert.Equal(t, 10, tickSpacing)
+	_, ok = feeTiers.TickSpacing(constants.Fee1)
+	assert.False(t, ok)
+
+	assert.NoError(t, feeTiers.Enable(constants.Fee1, 200))
+	tickSpacing, ok = feeTiers.TickSpacing(constants.Fee1)
+	assert.True(t, ok)
+	assert.Equal(t, 200, tickSpacing)
+
+	// enabling a fee tier again is only allowed with the same tick spacing
+	assert.NoError(t, feeTiers.Enable(constants.Fee1, 200))
+	assert.ErrorIs(t, feeTiers.Enable(constants.Fee1, 100), ErrFeeTierAlreadyEnabled)
+	assert.ErrorIs(t, feeTiers.Enable(constants.FeeMax, 1), ErrFeeTooHigh)
+	assert.ErrorIs(t, feeTiers.Enable(constants.Fee2, 0), ErrZeroTickSpacing)
+}
+
+func TestNewPool_FeeTiers(t *testing.T) {
+	sqrtRatioX96 := utils.EncodeSqrtRatioX96(constants.One, constants.One)
+
+	pool, err := NewPool(USDC, DAI, constants.Fee025, sqrtRatioX96, big.NewInt(0), big.NewInt(0), 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 25, pool.TickSpacing)
+
+	_, err = NewPool(USDC, DAI, 77, sqrtRatioX96, big.NewInt(0), big.NewInt(0), 0, nil)
+	assert.ErrorIs(t, err, ErrUnknownFeeTier)
+
+	// a pool that was not built by NewPool fails instead of panicking
+	_, err = NewPosition(&Pool{Token0: DAI, Token1: USDC, Fee: 77}, OneEther, -10, 10)
+	assert.ErrorIs(t, err, ErrUnknownFeeTier)
+
+	pool, err = NewPoolWithFeeTiers(
+		USDC, DAI, 77, sqrtRatioX96, big.NewInt(0), big.NewInt(0), 0, nil, fixedFeeTiers(7), constants.MaxTickDistance,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, pool.TickSpacing)
+	_, err = NewPosition(pool, OneEther, -14, 14)
+	assert.NoError(t, err)
+	_, err = NewPosition(pool, OneEther, -10, 10)
+	assert.ErrorIs(t, err, ErrTickLower)
+}
+
+func TestPool_WithState_KeepsTickSpacing(t *testing.T) {
+	sqrtRatioX96 := utils.EncodeSqrtRatioX96(constants.One, constants.One)
+	pool, err := NewPoolWithFeeTiers(
+		USDC, DAI, 77, sqrtRatioX96, big.NewInt(0), big.NewInt(0), 0, nil, fixedFeeTiers(7), 100,
+	)
+	assert.NoError(t, err)
+
+	// the fee tier of the pool is not in the default registry, the pools built from it keep its tick spacing
+	pool, err = pool.Mint(-14, 14, OneEther)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, pool.TickSpacing)
+	assert.Equal(t, 100, pool.TickDistance)
+
+	_, pool, err = pool.GetOutputAmount(entities.FromRawAmount(USDC, big.NewInt(1000)), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, pool.TickSpacing)
+	assert.Equal(t, 100, pool.TickDistance)
+
+	pool, err = pool.WithState(sqrtRatioX96, pool.Liquidity, pool.ReinvestLiquidity, 0, pool.TickDataProvider)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, pool.TickSpacing)
+	_, err = pool.Burn(-14, 14, OneEther)
+	assert.NoError(t, err)
+}
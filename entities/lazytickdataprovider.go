@@ -0,0 +1,282 @@
+package entities
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+var (
+	ErrInvalidFetchedTicks = errors.New("invalid fetched ticks")
+)
+
+// TickFetcher loads the initialized ticks of a pool, e.g. with a tick reader contract call
+type TickFetcher interface {
+	/**
+	 * Fetch the initialized ticks around a tick
+	 * @param tick The tick to fetch the ticks around
+	 * @returns The initialized ticks within [tickLower, tickUpper], sorted by index, where the range contains the tick.
+	 * Every initialized tick of the pool within the range must be returned
+	 */
+	FetchTicks(tick int) (ticks []Tick, tickLower int, tickUpper int, err error)
+}
+
+// tickRange is a range of ticks whose initialized ticks are loaded, inclusive
+type tickRange struct {
+	lower int
+	upper int
+}
+
+/**
+ * A data provider for ticks that loads the initialized ticks of a pool on demand through a TickFetcher, and caches the
+ * loaded ranges. Looking up the next initialized tick within a bound loads ranges until it finds one, or until the
+ * loaded ranges cover the bound, so a swap only loads the ticks around the prices it crosses. Fetch errors are
+ * returned by the methods that need the ticks. It is safe for concurrent use
+ */
+type LazyTickDataProvider struct {
+	fetcher     TickFetcher
+	tickSpacing int
+
+	mu     sync.RWMutex
+	ranges []tickRange // the loaded ranges, sorted and merged
+	ticks  []Tick      // the initialized ticks in the loaded ranges, sorted by index
+}
+
+/**
+ * Constructs a lazy tick data provider
+ * @param fetcher The fetcher the ticks are loaded with
+ * @param tickSpacing The tick spacing of the pool
+ */
+func NewLazyTickDataProvider(fetcher TickFetcher, tickSpacing int) (*LazyTickDataProvider, error) {
+	if tickSpacing <= 0 {
+		return nil, ErrZeroTickSpacing
+	}
+	return &LazyTickDataProvider{fetcher: fetcher, tickSpacing: tickSpacing}, nil
+}
+
+// covering returns the loaded range containing the tick, the lock must be held
+func (p *LazyTickDataProvider) covering(tick int) (tickRange, bool) {
+	i := sort.Search(len(p.ranges), func(i int) bool { return p.ranges[i].upper >= tick })
+	if i < len(p.ranges) && p.ranges[i].lower <= tick {
+		return p.ranges[i], true
+	}
+	return tickRange{}, false
+}
+
+// load returns the loaded range containing the tick, fetching the ticks around it if they are not loaded yet
+func (p *LazyTickDataProvider) load(tick int) (tickRange, error) {
+	p.mu.RLock()
+	loaded, ok := p.covering(tick)
+	p.mu.RUnlock()
+	if ok {
+		return loaded, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// the ticks may have been loaded while waiting for the lock
+	if loaded, ok := p.covering(tick); ok {
+		return loaded, nil
+	}
+	ticks, tickLower, tickUpper, err := p.fetcher.FetchTicks(tick)
+	if err != nil {
+		return tickRange{}, err
+	}
+	if tickLower > tick || tick > tickUpper {
+		return tickRange{}, ErrInvalidFetchedTicks
+	}
+	for i, t := range ticks {
+		if t.Index < tickLower || t.Index > tickUpper || t.Index%p.tickSpacing != 0 || (i > 0 && ticks[i-1].Index >= t.Index) {
+			return tickRange{}, ErrInvalidFetchedTicks
+		}
+	}
+	p.insertRange(tickRange{lower: tickLower, upper: tickUpper}, ticks)
+	loaded, _ = p.covering(tick)
+	return loaded, nil
+}
+
+// insertRange caches the ticks of a fetched range, the ticks of the parts of the range already loaded are kept
+func (p *LazyTickDataProvider) insertRange(fetched tickRange, ticks []Tick) {
+	for _, t := range ticks {
+		if _, ok := p.covering(t.Index); !ok {
+			p.setTick(t)
+		}
+	}
+
+	// merge the ranges that overlap or touch the fetched one
+	var merged []tickRange
+	for _, r := range p.ranges {
+		if r.upper+1 < fetched.lower || fetched.upper+1 < r.lower {
+			merged = append(merged, r)
+			continue
+		}
+		if r.lower < fetched.lower {
+			fetched.lower = r.lower
+		}
+		if r.upper > fetched.upper {
+			fetched.upper = r.upper
+		}
+	}
+	merged = append(merged, fetched)
+	sort.Slice(merged, func(i, j int) bool { return merged[i].lower < merged[j].lower })
+	p.ranges = merged
+}
+
+// setTick inserts, replaces or removes a cached tick, the lock must be held
+func (p *LazyTickDataProvider) setTick(tick Tick) {
+	i := sort.Search(len(p.ticks), func(i int) bool { return p.ticks[i].Index >= tick.Index })
+	found := i < len(p.ticks) && p.ticks[i].Index == tick.Index
+	switch {
+	case tick.LiquidityGross == nil || tick.LiquidityGross.Cmp(constants.Zero) == 0:
+		if found {
+			p.ticks = append(p.ticks[:i], p.ticks[i+1:]...)
+		}
+	case found:
+		p.ticks[i] = tick
+	default:
+		p.ticks = append(p.ticks, Tick{})
+		copy(p.ticks[i+1:], p.ticks[i:])
+		p.ticks[i] = tick
+	}
+}
+
+// floor returns the highest initialized tick at or below the given tick, ranges are loaded until the bound is covered.
+// ErrBelowSmallest is returned once a loaded range reaching utils.MinTick proves there is no initialized tick left
+func (p *LazyTickDataProvider) floor(tick, bound int) (Tick, bool, error) {
+	if bound < utils.MinTick {
+		bound = utils.MinTick
+	}
+	for tick >= bound {
+		loaded, err := p.load(tick)
+		if err != nil {
+			return EmptyTick, false, err
+		}
+
+		p.mu.RLock()
+		i := sort.Search(len(p.ticks), func(i int) bool { return p.ticks[i].Index > tick }) - 1
+		var found Tick
+		ok := i >= 0 && p.ticks[i].Index >= loaded.lower
+		if ok {
+			found = p.ticks[i]
+		}
+		p.mu.RUnlock()
+		if ok {
+			return found, true, nil
+		}
+		if loaded.lower <= utils.MinTick {
+			return EmptyTick, false, ErrBelowSmallest
+		}
+		tick = loaded.lower - 1
+	}
+	return EmptyTick, false, nil
+}
+
+// ceiling returns the lowest initialized tick above the given tick, ranges are loaded until the bound is covered.
+// ErrAtOrAboveLargest is returned once a loaded range reaching utils.MaxTick proves there is no initialized tick left
+func (p *LazyTickDataProvider) ceiling(tick, bound int) (Tick, bool, error) {
+	if bound > utils.MaxTick {
+		bound = utils.MaxTick
+	}
+	for tick < bound {
+		loaded, err := p.load(tick + 1)
+		if err != nil {
+			return EmptyTick, false, err
+		}
+
+		p.mu.RLock()
+		i := sort.Search(len(p.ticks), func(i int) bool { return p.ticks[i].Index > tick })
+		var found Tick
+		ok := i < len(p.ticks) && p.ticks[i].Index <= loaded.upper
+		if ok {
+			found = p.ticks[i]
+		}
+		p.mu.RUnlock()
+		if ok {
+			return found, true, nil
+		}
+		if loaded.upper >= utils.MaxTick {
+			return EmptyTick, false, ErrAtOrAboveLargest
+		}
+		tick = loaded.upper
+	}
+	return EmptyTick, false, nil
+}
+
+// GetTick returns the initialized tick at or immediately below the tick, ErrBelowSmallest if there is none
+func (p *LazyTickDataProvider) GetTick(tick int) (Tick, error) {
+	found, ok, err := p.floor(tick, utils.MinTick)
+	if err != nil {
+		return EmptyTick, err
+	}
+	if !ok {
+		return EmptyTick, ErrBelowSmallest
+	}
+	return found, nil
+}
+
+func (p *LazyTickDataProvider) NextInitializedTickWithinOneWord(tick int, lte bool, tickSpacing int) (int, bool, error) {
+	return p.nextInitializedTickWithinBound(tick, lte, oneWordBound(tick, lte, tickSpacing))
+}
+
+func (p *LazyTickDataProvider) NextInitializedTickWithinFixedDistance(tick int, lte bool, distance int) (int, bool, error) {
+	if lte {
+		return p.nextInitializedTickWithinBound(tick, lte, tick-distance)
+	}
+	return p.nextInitializedTickWithinBound(tick, lte, tick+distance)
+}
+
+// nextInitializedTickWithinBound only loads the ranges up to the bound, ErrBelowSmallest and ErrAtOrAboveLargest are
+// returned like the other providers when the loaded ranges prove there is no initialized tick left
+func (p *LazyTickDataProvider) nextInitializedTickWithinBound(tick int, lte bool, bound int) (int, bool, error) {
+	if lte {
+		found, ok, err := p.floor(tick, bound)
+		if err == nil && !ok {
+			if bound > utils.MinTick {
+				return bound, false, nil
+			}
+			err = ErrBelowSmallest
+		}
+		return initializedTickWithinBound(found.Index, err, lte, bound)
+	}
+	found, ok, err := p.ceiling(tick, bound)
+	if err == nil && !ok {
+		if bound < utils.MaxTick {
+			return bound, false, nil
+		}
+		err = ErrAtOrAboveLargest
+	}
+	return initializedTickWithinBound(found.Index, err, lte, bound)
+}
+
+/**
+ * Return a copy of the provider with the given ticks inserted or replaced. The ranges of the ticks are loaded first,
+ * the copy shares the fetcher but has its own cache, so ticks it loads later are not affected by the updates
+ * @param ticks The new state of the ticks, ticks with zero LiquidityGross are removed
+ */
+func (p *LazyTickDataProvider) UpdateTicks(ticks []Tick) (TickDataProvider, error) {
+	for _, tick := range ticks {
+		if tick.Index%p.tickSpacing != 0 {
+			return nil, ErrInvalidTickSpacing
+		}
+		if _, err := p.load(tick.Index); err != nil {
+			return nil, err
+		}
+	}
+
+	p.mu.RLock()
+	updated := &LazyTickDataProvider{
+		fetcher:     p.fetcher,
+		tickSpacing: p.tickSpacing,
+		ranges:      append([]tickRange(nil), p.ranges...),
+		ticks:       append([]Tick(nil), p.ticks...),
+	}
+	p.mu.RUnlock()
+
+	for _, tick := range ticks {
+		updated.setTick(tick)
+	}
+	return updated, nil
+}
@@ -0,0 +1,278 @@
+package entities
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+// wordTickFetcher fetches the ticks of the word of the tick bitmap around a tick from memory
+type wordTickFetcher struct {
+	ticks       []Tick
+	tickSpacing int
+	err         error
+	calls       int64
+}
+
+func (f *wordTickFetcher) FetchTicks(tick int) ([]Tick, int, int, error) {
+	atomic.AddInt64(&f.calls, 1)
+	if f.err != nil {
+		return nil, 0, 0, f.err
+	}
+	tickLower := oneWordBound(tick, true, f.tickSpacing)
+	tickUpper := tickLower + 256*f.tickSpacing - 1
+	var ticks []Tick
+	for _, t := range f.ticks {
+		if tickLower <= t.Index && t.Index <= tickUpper {
+			ticks = append(ticks, t)
+		}
+	}
+	return ticks, tickLower, tickUpper, nil
+}
+
+func TestLazyTickDataProvider_MatchesTickList(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	var ticks []Tick
+	for index := -50000; index <= 50000; index += 10 {
+		if r.Intn(200) == 0 {
+			ticks = append(ticks, Tick{Index: index, LiquidityGross: OneEther, LiquidityNet: OneEther})
+		}
+	}
+	ticks = append(ticks, Tick{
+		Index:          50010,
+		LiquidityGross: OneEther,
+		LiquidityNet:   new(big.Int).Mul(OneEther, big.NewInt(-int64(len(ticks)))),
+	})
+
+	list, err := NewTickListDataProvider(ticks, 10)
+	assert.NoError(t, err)
+	lazy, err := NewLazyTickDataProvider(&wordTickFetcher{ticks: ticks, tickSpacing: 10}, 10)
+	assert.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		tick := r.Intn(120000) - 60000
+		lte := r.Intn(2) == 0
+
+		expectedTick, expectedErr := list.GetTick(tick)
+		actualTick, actualErr := lazy.GetTick(tick)
+		assert.Equal(t, expectedErr, actualErr)
+		assert.Equal(t, expectedTick.Index, actualTick.Index)
+
+		// the lazy provider doesn't load the ticks beyond the bound to tell whether there is no initialized tick left
+		expectedIndex, expectedInitialized, expectedErr := list.NextInitializedTickWithinFixedDistance(tick, lte, 480)
+		actualIndex, actualInitialized, actualErr := lazy.NextInitializedTickWithinFixedDistance(tick, lte, 480)
+		assertSameBoundErr(t, expectedErr, actualErr)
+		assert.Equal(t, expectedIndex, actualIndex, "tick %d lte %v", tick, lte)
+		assert.Equal(t, expectedInitialized, actualInitialized)
+
+		expectedIndex, expectedInitialized, expectedErr = list.NextInitializedTickWithinOneWord(tick, lte, 10)
+		actualIndex, actualInitialized, actualErr = lazy.NextInitializedTickWithinOneWord(tick, lte, 10)
+		assertSameBoundErr(t, expectedErr, actualErr)
+		assert.Equal(t, expectedIndex, actualIndex)
+		assert.Equal(t, expectedInitialized, actualInitialized)
+	}
+}
+
+// assertSameBoundErr asserts the errors are equal, or that the lazy provider returned the bound without an error
+func assertSameBoundErr(t *testing.T, expected, actual error) {
+	if actual == nil && (expected == ErrBelowSmallest || expected == ErrAtOrAboveLargest) {
+		return
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestLazyTickDataProvider_Bound(t *testing.T) {
+	ticks := []Tick{
+		{Index: NearestUsableTick(utils.MinTick, 10), LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: NearestUsableTick(utils.MaxTick, 10), LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	fetcher := &wordTickFetcher{ticks: ticks, tickSpacing: 10}
+	lazy, err := NewLazyTickDataProvider(fetcher, 10)
+	assert.NoError(t, err)
+
+	// only the words of the tick and of the bound are loaded
+	index, initialized, err := lazy.NextInitializedTickWithinFixedDistance(0, true, 480)
+	assert.NoError(t, err)
+	assert.Equal(t, -480, index)
+	assert.False(t, initialized)
+	assert.Equal(t, int64(2), fetcher.calls)
+	index, initialized, err = lazy.NextInitializedTickWithinFixedDistance(0, false, 480)
+	assert.NoError(t, err)
+	assert.Equal(t, 480, index)
+	assert.False(t, initialized)
+	assert.Equal(t, int64(2), fetcher.calls)
+
+	// a bound beyond the last tick loads every range up to it
+	index, initialized, err = lazy.NextInitializedTickWithinFixedDistance(0, false, 2*utils.MaxTick)
+	assert.NoError(t, err)
+	assert.Equal(t, ticks[1].Index, index)
+	assert.True(t, initialized)
+	_, _, err = lazy.NextInitializedTickWithinFixedDistance(ticks[1].Index, false, 2*utils.MaxTick)
+	assert.ErrorIs(t, err, ErrAtOrAboveLargest)
+}
+
+func TestLazyTickDataProvider_Caching(t *testing.T) {
+	ticks := []Tick{
+		{Index: -100, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 100, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	fetcher := &wordTickFetcher{ticks: ticks, tickSpacing: 10}
+	lazy, err := NewLazyTickDataProvider(fetcher, 10)
+	assert.NoError(t, err)
+
+	// both ticks are in the words around tick 0
+	tick, err := lazy.GetTick(100)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, tick.Index)
+	tick, err = lazy.GetTick(-50)
+	assert.NoError(t, err)
+	assert.Equal(t, -100, tick.Index)
+	assert.Equal(t, int64(2), fetcher.calls)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tick, err := lazy.GetTick(50)
+			assert.NoError(t, err)
+			assert.Equal(t, -100, tick.Index)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(2), fetcher.calls)
+
+	// fetch errors are returned
+	fetcher.err = errors.New("fetch failed")
+	_, _, err = lazy.NextInitializedTickWithinOneWord(100000, true, 10)
+	assert.ErrorIs(t, err, fetcher.err)
+	_, err = lazy.GetTick(100)
+	assert.NoError(t, err)
+
+	_, err = NewLazyTickDataProvider(fetcher, 0)
+	assert.ErrorIs(t, err, ErrZeroTickSpacing)
+}
+
+type invalidTickFetcher struct{}
+
+func (invalidTickFetcher) FetchTicks(tick int) ([]Tick, int, int, error) {
+	return []Tick{{Index: tick + 1000, LiquidityGross: OneEther, LiquidityNet: OneEther}}, tick, tick + 10, nil
+}
+
+func TestLazyTickDataProvider_InvalidFetchedTicks(t *testing.T) {
+	lazy, err := NewLazyTickDataProvider(invalidTickFetcher{}, 10)
+	assert.NoError(t, err)
+	_, err = lazy.GetTick(0)
+	assert.ErrorIs(t, err, ErrInvalidFetchedTicks)
+}
+
+func TestLazyTickDataProvider_Pool(t *testing.T) {
+	ticks := []Tick{
+		{Index: -100, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 100, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+		{Index: 200, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 300, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	lazy, err := NewLazyTickDataProvider(&wordTickFetcher{ticks: ticks, tickSpacing: 10}, 10)
+	assert.NoError(t, err)
+	pool, err := NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther,
+		big.NewInt(1e12), 0, lazy,
+	)
+	assert.NoError(t, err)
+
+	// swaps give the same results as with a tick list
+	inputAmount := entities.FromRawAmount(USDC, big.NewInt(7e15))
+	expected, err := newCrossTickTestPool(t).SimulateExactIn(inputAmount, nil)
+	assert.NoError(t, err)
+	actual, err := pool.SimulateExactIn(inputAmount, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.OutputAmount.Quotient(), actual.OutputAmount.Quotient())
+	assert.Equal(t, expected.Pool.SqrtRatioX96, actual.Pool.SqrtRatioX96)
+
+	// minting updates a copy of the cache
+	minted, err := pool.Mint(-50, 50, OneEther)
+	assert.NoError(t, err)
+	tick, err := minted.TickDataProvider.GetTick(-50)
+	assert.NoError(t, err)
+	assert.Equal(t, -50, tick.Index)
+	tick, err = lazy.GetTick(-50)
+	assert.NoError(t, err)
+	assert.Equal(t, -100, tick.Index)
+}
+
+// neighbourTickFetcher fetches the range between the initialized ticks around a tick from memory, like a tick reader
+// walking the linked list of a pool, the range reaches utils.MinTick or utils.MaxTick past the last ticks
+type neighbourTickFetcher struct {
+	ticks []Tick
+}
+
+func (f *neighbourTickFetcher) FetchTicks(tick int) ([]Tick, int, int, error) {
+	tickLower, tickUpper := utils.MinTick, utils.MaxTick
+	var ticks []Tick
+	for _, t := range f.ticks {
+		if t.Index <= tick {
+			tickLower = t.Index
+			ticks = []Tick{t}
+		} else {
+			tickUpper = t.Index
+			ticks = append(ticks, t)
+			break
+		}
+	}
+	return ticks, tickLower, tickUpper, nil
+}
+
+func TestLazyTickDataProvider_SameSwapAsOtherProviders(t *testing.T) {
+	ticks := []Tick{
+		{Index: -100, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 100, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	list, err := NewTickListDataProvider(ticks, 10)
+	assert.NoError(t, err)
+	linkedList, err := NewLinkedListTickDataProvider(ticks, 10)
+	assert.NoError(t, err)
+	lazy, err := NewLazyTickDataProvider(&neighbourTickFetcher{ticks: ticks}, 10)
+	assert.NoError(t, err)
+
+	for _, amount := range []*big.Int{big.NewInt(1e15), OneEther} {
+		var outputs []*entities.CurrencyAmount
+		var errs []error
+		for _, provider := range []TickDataProvider{list, linkedList, lazy} {
+			pool, err := NewPool(
+				USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther,
+				big.NewInt(1e15), 0, provider,
+			)
+			assert.NoError(t, err)
+			output, _, err := pool.GetOutputAmount(entities.FromRawAmount(DAI, amount), nil)
+			outputs = append(outputs, output)
+			errs = append(errs, err)
+		}
+		for i := 1; i < len(outputs); i++ {
+			assert.Equal(t, errs[0], errs[i], "amount %v", amount)
+			if outputs[0] != nil {
+				assert.Equal(t, outputs[0].Quotient(), outputs[i].Quotient(), "amount %v", amount)
+			}
+		}
+	}
+
+	// selling token0 past the lowest tick fails the same way with every provider
+	for _, provider := range []TickDataProvider{list, linkedList, lazy} {
+		pool, err := NewPool(
+			USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther,
+			big.NewInt(1e15), 0, provider,
+		)
+		assert.NoError(t, err)
+		_, _, err = pool.GetOutputAmount(entities.FromRawAmount(DAI, OneEther), nil)
+		assert.ErrorIs(t, err, ErrBelowSmallest)
+	}
+}
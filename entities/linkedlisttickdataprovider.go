@@ -0,0 +1,420 @@
+package entities
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync/atomic"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+var (
+	ErrTickDataProviderShared = errors.New("tick data provider is shared with its updated copies")
+)
+
+const (
+	// the neighbours of the lowest and the highest initialized ticks, outside of the range of the valid ticks as the
+	// lowest and highest usable ticks can be initialized
+	listHead = utils.MinTick - 1
+	listTail = utils.MaxTick + 1
+
+	// the number of updated copies a provider can be layered on before its ticks are copied to a new map
+	maxLinkedListDepth = 16
+)
+
+// linkedTick is an initialized tick, with the indexes of its neighbours in the list
+type linkedTick struct {
+	Tick
+	previous int // listHead for the lowest tick
+	next     int // listTail for the highest tick
+}
+
+// A data provider for ticks that keeps the initialized ticks in a doubly linked list, as the ProMM pool contract does.
+// The list is indexed by tick, so initialized ticks and their neighbours are found in O(1). The nearest initialized
+// tick of an uninitialized tick is found with a binary search of the sorted ticks of the bottom provider, combined with
+// the ticks that changed in the updated copies above it. Updated copies only hold the ticks that changed, on top of the
+// provider they were updated from. Reads are safe for concurrent use, Insert and Delete are not safe for concurrent
+// use with any other method, and fail on a provider that updated copies were made of
+type LinkedListTickDataProvider struct {
+	ticks       map[int]*linkedTick // the ticks that differ from base, nil for ticks deleted from base
+	sorted      []int               // the initialized ticks of a provider without base, sorted
+	base        *LinkedListTickDataProvider
+	depth       int // the number of providers below this one
+	head        int // the lowest initialized tick, listTail if there is none
+	tail        int // the highest initialized tick, listHead if there is none
+	size        int
+	tickSpacing int
+
+	// set once an updated copy is layered on the provider, as it can't be modified anymore
+	shared int32
+}
+
+/**
+ * Constructs a linked list tick data provider
+ * @param ticks The initialized ticks, sorted by index
+ * @param tickSpacing The tick spacing of the pool
+ */
+func NewLinkedListTickDataProvider(ticks []Tick, tickSpacing int) (*LinkedListTickDataProvider, error) {
+	if err := ValidateList(ticks, tickSpacing); err != nil {
+		return nil, err
+	}
+	return newLinkedList(ticks, tickSpacing), nil
+}
+
+// newLinkedList returns a provider holding the ticks in a single map, the ticks must be sorted by index
+func newLinkedList(ticks []Tick, tickSpacing int) *LinkedListTickDataProvider {
+	p := &LinkedListTickDataProvider{
+		ticks:       make(map[int]*linkedTick, len(ticks)),
+		sorted:      make([]int, len(ticks)),
+		head:        listTail,
+		tail:        listHead,
+		size:        len(ticks),
+		tickSpacing: tickSpacing,
+	}
+	for i, tick := range ticks {
+		node := &linkedTick{Tick: tick, previous: listHead, next: listTail}
+		if i > 0 {
+			node.previous = ticks[i-1].Index
+		}
+		if i < len(ticks)-1 {
+			node.next = ticks[i+1].Index
+		}
+		p.ticks[tick.Index] = node
+		p.sorted[i] = tick.Index
+	}
+	if len(ticks) > 0 {
+		p.head, p.tail = ticks[0].Index, ticks[len(ticks)-1].Index
+	}
+	return p
+}
+
+// get returns an initialized tick, nil if the tick is not initialized
+func (p *LinkedListTickDataProvider) get(index int) *linkedTick {
+	for layer := p; layer != nil; layer = layer.base {
+		if node, ok := layer.ticks[index]; ok {
+			return node
+		}
+	}
+	return nil
+}
+
+// bottom returns the provider without base the provider is layered on, the provider itself if it has no base
+func (p *LinkedListTickDataProvider) bottom() *LinkedListTickDataProvider {
+	for p.base != nil {
+		p = p.base
+	}
+	return p
+}
+
+/**
+ * Returns the nearest initialized tick below or above a tick, from the sorted ticks of the bottom provider that are
+ * not deleted, and the ticks initialized by the updated copies above it
+ * @param tick The tick, excluded
+ * @param lte Whether the nearest tick below the tick is returned, otherwise the one above
+ * @returns The nearest initialized tick, nil if there is none
+ */
+func (p *LinkedListTickDataProvider) nearest(tick int, lte bool) *linkedTick {
+	bottom := p.bottom()
+	found := listTail
+	if lte {
+		found = listHead
+	}
+	closer := func(index int) bool {
+		if lte {
+			return index < tick && index > found
+		}
+		return index > tick && index < found
+	}
+
+	// the ticks of the bottom provider deleted by the updated copies are skipped
+	if lte {
+		for i := sort.SearchInts(bottom.sorted, tick) - 1; i >= 0; i-- {
+			if p.get(bottom.sorted[i]) != nil {
+				found = bottom.sorted[i]
+				break
+			}
+		}
+	} else {
+		for i := sort.SearchInts(bottom.sorted, tick+1); i < len(bottom.sorted); i++ {
+			if p.get(bottom.sorted[i]) != nil {
+				found = bottom.sorted[i]
+				break
+			}
+		}
+	}
+	for layer := p; layer != bottom; layer = layer.base {
+		for index, node := range layer.ticks {
+			if node != nil && closer(index) && p.get(index) != nil {
+				found = index
+			}
+		}
+	}
+	if found == listHead || found == listTail {
+		return nil
+	}
+	return p.get(found)
+}
+
+// floor returns the highest initialized tick at or below the given tick and at or above the bound, nil if there is none
+func (p *LinkedListTickDataProvider) floor(tick, bound int) *linkedTick {
+	if p.size == 0 || tick < p.head || p.tail < bound || tick < bound {
+		return nil
+	}
+	if node := p.get(tick); node != nil {
+		return node
+	}
+	if tick >= p.tail {
+		return p.get(p.tail)
+	}
+	node := p.nearest(tick, true)
+	if node == nil || node.Index < bound {
+		return nil
+	}
+	return node
+}
+
+// ceiling returns the lowest initialized tick above the given tick and at or below the bound, nil if there is none
+func (p *LinkedListTickDataProvider) ceiling(tick, bound int) *linkedTick {
+	if p.size == 0 || tick >= p.tail || bound < p.head || bound <= tick {
+		return nil
+	}
+	if tick < p.head {
+		return p.get(p.head)
+	}
+	// the neighbour of an initialized tick is linked to it
+	node := p.get(tick)
+	if node != nil {
+		node = p.get(node.next)
+	} else {
+		node = p.nearest(tick, false)
+	}
+	if node == nil || node.Index > bound {
+		return nil
+	}
+	return node
+}
+
+func (p *LinkedListTickDataProvider) GetTick(tick int) (Tick, error) {
+	if p.size == 0 {
+		return EmptyTick, ErrEmptyTickList
+	}
+	node := p.floor(tick, p.head)
+	if node == nil {
+		return EmptyTick, ErrBelowSmallest
+	}
+	return node.Tick, nil
+}
+
+/**
+ * Return the initialized ticks immediately below and above an initialized tick. As in the list of the pool contract,
+ * the lowest tick is preceded by utils.MinTick and the highest tick is followed by utils.MaxTick
+ * @param tick The initialized tick
+ */
+func (p *LinkedListTickDataProvider) Neighbours(tick int) (previous int, next int, err error) {
+	node := p.get(tick)
+	if node == nil {
+		return ZeroValueTickIndex, ZeroValueTickIndex, ErrInvalidTickIndex
+	}
+	previous, next = node.previous, node.next
+	if previous == listHead {
+		previous = utils.MinTick
+	}
+	if next == listTail {
+		next = utils.MaxTick
+	}
+	return previous, next, nil
+}
+
+func (p *LinkedListTickDataProvider) NextInitializedTickWithinOneWord(tick int, lte bool, tickSpacing int) (int, bool, error) {
+	return p.nextInitializedTickWithinBound(tick, lte, oneWordBound(tick, lte, tickSpacing))
+}
+
+func (p *LinkedListTickDataProvider) NextInitializedTickWithinFixedDistance(tick int, lte bool, distance int) (int, bool, error) {
+	if lte {
+		return p.nextInitializedTickWithinBound(tick, lte, tick-distance)
+	}
+	return p.nextInitializedTickWithinBound(tick, lte, tick+distance)
+}
+
+func (p *LinkedListTickDataProvider) nextInitializedTickWithinBound(tick int, lte bool, bound int) (int, bool, error) {
+	if p.size == 0 {
+		return initializedTickWithinBound(ZeroValueTickIndex, ErrEmptyTickList, lte, bound)
+	}
+	var node *linkedTick
+	if lte {
+		if tick < p.head {
+			return initializedTickWithinBound(ZeroValueTickIndex, ErrBelowSmallest, lte, bound)
+		}
+		node = p.floor(tick, bound)
+	} else {
+		if tick >= p.tail {
+			return initializedTickWithinBound(ZeroValueTickIndex, ErrAtOrAboveLargest, lte, bound)
+		}
+		node = p.ceiling(tick, bound)
+	}
+	if node == nil {
+		return bound, false, nil
+	}
+	return node.Index, true, nil
+}
+
+/**
+ * Returns the next initialized tick if it is within the bound, as the tick list does
+ * @param next The next initialized tick
+ * @param err The error of finding the next initialized tick, ErrBelowSmallest and ErrAtOrAboveLargest return the bound
+ * @param lte Whether the next tick is lte the current tick
+ * @param bound The furthest tick that can be returned
+ */
+func initializedTickWithinBound(next int, err error, lte bool, bound int) (int, bool, error) {
+	if err == ErrBelowSmallest || err == ErrAtOrAboveLargest {
+		return bound, ZeroValueTickInitialized, err
+	}
+	if err != nil {
+		return ZeroValueTickIndex, ZeroValueTickInitialized, err
+	}
+	if (lte && next < bound) || (!lte && next > bound) {
+		return bound, false, nil
+	}
+	return next, true, nil
+}
+
+// oneWordBound returns the furthest tick within the word of the tick bitmap of a tick, as the tick list does
+func oneWordBound(tick int, lte bool, tickSpacing int) int {
+	compressed := math.Floor(float64(tick) / float64(tickSpacing)) // matches rounding in the code
+
+	if lte {
+		wordPos := int(compressed) >> 8
+		return (wordPos << 8) * tickSpacing
+	}
+	wordPos := int(compressed+1) >> 8
+	return ((wordPos+1)<<8)*tickSpacing - 1
+}
+
+// setNeighbours sets the neighbours of an initialized tick, the nodes of the lower providers are never modified
+func (p *LinkedListTickDataProvider) setNeighbours(index, previous, next int) {
+	node := p.get(index)
+	p.ticks[index] = &linkedTick{Tick: node.Tick, previous: previous, next: next}
+}
+
+/**
+ * Inserts or replaces an initialized tick
+ * @param tick The tick, its LiquidityGross must not be zero
+ */
+func (p *LinkedListTickDataProvider) Insert(tick Tick) error {
+	if atomic.LoadInt32(&p.shared) != 0 {
+		return ErrTickDataProviderShared
+	}
+	if tick.Index%p.tickSpacing != 0 {
+		return ErrInvalidTickSpacing
+	}
+	if tick.LiquidityGross == nil || tick.LiquidityGross.Cmp(constants.Zero) == 0 {
+		return ErrInvalidLiquidity
+	}
+	if node := p.get(tick.Index); node != nil {
+		p.ticks[tick.Index] = &linkedTick{Tick: tick, previous: node.previous, next: node.next}
+		return nil
+	}
+
+	node := &linkedTick{Tick: tick, previous: listHead, next: p.head}
+	if previous := p.floor(tick.Index, p.head); previous != nil {
+		node.previous, node.next = previous.Index, previous.next
+		p.setNeighbours(previous.Index, previous.previous, tick.Index)
+	} else {
+		p.head = tick.Index
+	}
+	if node.next == listTail {
+		p.tail = tick.Index
+	} else {
+		next := p.get(node.next)
+		p.setNeighbours(next.Index, tick.Index, next.next)
+	}
+	p.ticks[tick.Index] = node
+	if p.base == nil {
+		i := sort.SearchInts(p.sorted, tick.Index)
+		p.sorted = append(p.sorted, 0)
+		copy(p.sorted[i+1:], p.sorted[i:])
+		p.sorted[i] = tick.Index
+	}
+	p.size++
+	return nil
+}
+
+/**
+ * Deletes an initialized tick
+ * @param index The index of the tick
+ */
+func (p *LinkedListTickDataProvider) Delete(index int) error {
+	if atomic.LoadInt32(&p.shared) != 0 {
+		return ErrTickDataProviderShared
+	}
+	node := p.get(index)
+	if node == nil {
+		return ErrInvalidTickIndex
+	}
+	if node.previous == listHead {
+		p.head = node.next
+	} else {
+		previous := p.get(node.previous)
+		p.setNeighbours(previous.Index, previous.previous, node.next)
+	}
+	if node.next == listTail {
+		p.tail = node.previous
+	} else {
+		next := p.get(node.next)
+		p.setNeighbours(next.Index, node.previous, next.next)
+	}
+	if p.base == nil {
+		delete(p.ticks, index)
+		i := sort.SearchInts(p.sorted, index)
+		p.sorted = append(p.sorted[:i], p.sorted[i+1:]...)
+	} else {
+		p.ticks[index] = nil
+	}
+	p.size--
+	return nil
+}
+
+func (p *LinkedListTickDataProvider) UpdateTicks(ticks []Tick) (TickDataProvider, error) {
+	var updated *LinkedListTickDataProvider
+	if p.depth >= maxLinkedListDepth {
+		updated = newLinkedList(p.Ticks(), p.tickSpacing)
+	} else {
+		atomic.StoreInt32(&p.shared, 1)
+		updated = &LinkedListTickDataProvider{
+			ticks:       make(map[int]*linkedTick, 3*len(ticks)),
+			base:        p,
+			depth:       p.depth + 1,
+			head:        p.head,
+			tail:        p.tail,
+			size:        p.size,
+			tickSpacing: p.tickSpacing,
+		}
+	}
+	for _, tick := range ticks {
+		if tick.LiquidityGross == nil || tick.LiquidityGross.Cmp(constants.Zero) == 0 {
+			if updated.get(tick.Index) != nil {
+				if err := updated.Delete(tick.Index); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err := updated.Insert(tick); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+// Ticks returns the ticks of the provider, sorted by index
+func (p *LinkedListTickDataProvider) Ticks() []Tick {
+	ticks := make([]Tick, 0, p.size)
+	for index := p.head; index != listTail; {
+		node := p.get(index)
+		ticks = append(ticks, node.Tick)
+		index = node.next
+	}
+	return ticks
+}
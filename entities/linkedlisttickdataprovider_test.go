@@ -0,0 +1,279 @@
+package entities
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+func TestLinkedListTickDataProvider_MatchesTickList(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var ticks []Tick
+	for index := -5000; index <= 5000; index += 10 {
+		if r.Intn(10) == 0 {
+			ticks = append(ticks, Tick{Index: index, LiquidityGross: OneEther, LiquidityNet: OneEther})
+		}
+	}
+	// make the liquidity net sum to zero
+	ticks = append(ticks, Tick{
+		Index:          5010,
+		LiquidityGross: OneEther,
+		LiquidityNet:   new(big.Int).Mul(OneEther, big.NewInt(-int64(len(ticks)))),
+	})
+
+	list, err := NewTickListDataProvider(ticks, 10)
+	assert.NoError(t, err)
+	linked, err := NewLinkedListTickDataProvider(ticks, 10)
+	assert.NoError(t, err)
+
+	for i := 0; i < 2000; i++ {
+		tick := r.Intn(12000) - 6000
+		lte := r.Intn(2) == 0
+
+		expectedTick, expectedErr := list.GetTick(tick)
+		actualTick, actualErr := linked.GetTick(tick)
+		assert.Equal(t, expectedErr, actualErr)
+		assert.Equal(t, expectedTick.Index, actualTick.Index)
+
+		expectedIndex, expectedInitialized, expectedErr := list.NextInitializedTickWithinFixedDistance(tick, lte, 480)
+		actualIndex, actualInitialized, actualErr := linked.NextInitializedTickWithinFixedDistance(tick, lte, 480)
+		assert.Equal(t, expectedErr, actualErr)
+		assert.Equal(t, expectedIndex, actualIndex, "tick %d lte %v", tick, lte)
+		assert.Equal(t, expectedInitialized, actualInitialized)
+
+		expectedIndex, expectedInitialized, expectedErr = list.NextInitializedTickWithinOneWord(tick, lte, 10)
+		actualIndex, actualInitialized, actualErr = linked.NextInitializedTickWithinOneWord(tick, lte, 10)
+		assert.Equal(t, expectedErr, actualErr)
+		assert.Equal(t, expectedIndex, actualIndex)
+		assert.Equal(t, expectedInitialized, actualInitialized)
+	}
+	assert.Equal(t, ticks, linked.Ticks())
+}
+
+func TestLinkedListTickDataProvider_InsertDelete(t *testing.T) {
+	linked, err := NewLinkedListTickDataProvider(nil, 10)
+	assert.NoError(t, err)
+	_, err = linked.GetTick(0)
+	assert.ErrorIs(t, err, ErrEmptyTickList)
+
+	assert.NoError(t, linked.Insert(Tick{Index: 100, LiquidityGross: OneEther, LiquidityNet: OneEther}))
+	assert.NoError(t, linked.Insert(Tick{Index: -100, LiquidityGross: OneEther, LiquidityNet: OneEther}))
+	assert.NoError(t, linked.Insert(Tick{Index: 0, LiquidityGross: OneEther, LiquidityNet: OneEther}))
+	assert.ErrorIs(t, linked.Insert(Tick{Index: 5, LiquidityGross: OneEther, LiquidityNet: OneEther}), ErrInvalidTickSpacing)
+	assert.ErrorIs(t, linked.Insert(Tick{Index: 10, LiquidityGross: big.NewInt(0), LiquidityNet: OneEther}), ErrInvalidLiquidity)
+
+	previous, next, err := linked.Neighbours(0)
+	assert.NoError(t, err)
+	assert.Equal(t, -100, previous)
+	assert.Equal(t, 100, next)
+	previous, next, err = linked.Neighbours(-100)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.MinTick, previous)
+	assert.Equal(t, 0, next)
+	_, _, err = linked.Neighbours(50)
+	assert.ErrorIs(t, err, ErrInvalidTickIndex)
+
+	assert.NoError(t, linked.Delete(0))
+	assert.ErrorIs(t, linked.Delete(0), ErrInvalidTickIndex)
+	previous, next, err = linked.Neighbours(100)
+	assert.NoError(t, err)
+	assert.Equal(t, -100, previous)
+	assert.Equal(t, utils.MaxTick, next)
+	tick, err := linked.GetTick(50)
+	assert.NoError(t, err)
+	assert.Equal(t, -100, tick.Index)
+
+	assert.NoError(t, linked.Delete(100))
+	assert.NoError(t, linked.Delete(-100))
+	assert.Empty(t, linked.Ticks())
+}
+
+func TestLinkedListTickDataProvider_Pool(t *testing.T) {
+	ticks := []Tick{
+		{Index: -100, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 100, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+		{Index: 200, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 300, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	linked, err := NewLinkedListTickDataProvider(ticks, 10)
+	assert.NoError(t, err)
+	pool, err := NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther,
+		big.NewInt(1e12), 0, linked,
+	)
+	assert.NoError(t, err)
+
+	// swaps give the same results as with a tick list
+	inputAmount := entities.FromRawAmount(USDC, big.NewInt(7e15))
+	expected, err := newCrossTickTestPool(t).SimulateExactIn(inputAmount, nil)
+	assert.NoError(t, err)
+	actual, err := pool.SimulateExactIn(inputAmount, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.OutputAmount.Quotient(), actual.OutputAmount.Quotient())
+	assert.Equal(t, expected.Pool.SqrtRatioX96, actual.Pool.SqrtRatioX96)
+
+	// minting updates a copy of the list
+	minted, err := pool.Mint(-50, 50, OneEther)
+	assert.NoError(t, err)
+	previous, next, err := minted.TickDataProvider.(*LinkedListTickDataProvider).Neighbours(-50)
+	assert.NoError(t, err)
+	assert.Equal(t, -100, previous)
+	assert.Equal(t, 50, next)
+	_, _, err = linked.Neighbours(-50)
+	assert.ErrorIs(t, err, ErrInvalidTickIndex)
+}
+
+func TestLinkedListTickDataProvider_UpdateTicks(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	expected := map[int]Tick{}
+	linked, err := NewLinkedListTickDataProvider(nil, 10)
+	assert.NoError(t, err)
+
+	// more updates than the copies can be layered on, the ticks of the older copies must not change
+	var copies []*LinkedListTickDataProvider
+	var copiesTicks [][]Tick
+	for i := 0; i < 3*maxLinkedListDepth; i++ {
+		var updates []Tick
+		for j := 0; j < 5; j++ {
+			index := (r.Intn(200) - 100) * 10
+			if _, ok := expected[index]; ok && r.Intn(2) == 0 {
+				delete(expected, index)
+				updates = append(updates, Tick{Index: index, LiquidityGross: big.NewInt(0), LiquidityNet: big.NewInt(0)})
+				continue
+			}
+			tick := Tick{Index: index, LiquidityGross: big.NewInt(int64(i + 1)), LiquidityNet: big.NewInt(int64(j))}
+			expected[index] = tick
+			updates = append(updates, tick)
+		}
+		updated, err := linked.UpdateTicks(updates)
+		assert.NoError(t, err)
+		linked = updated.(*LinkedListTickDataProvider)
+
+		var ticks []Tick
+		for index := -1000; index < 1000; index += 10 {
+			if tick, ok := expected[index]; ok {
+				ticks = append(ticks, tick)
+			}
+		}
+		assert.Equal(t, len(ticks), len(linked.Ticks()))
+		if len(ticks) > 0 {
+			assert.Equal(t, ticks, linked.Ticks())
+		}
+		copies, copiesTicks = append(copies, linked), append(copiesTicks, ticks)
+
+		// the nearest initialized ticks of the layered copy are the ones of the expected list
+		for probe := -1005; probe < 1005; probe += 5 {
+			below, above := -1, len(ticks)
+			for k, tick := range ticks {
+				if tick.Index <= probe {
+					below = k
+				} else if above == len(ticks) {
+					above = k
+				}
+			}
+			if len(ticks) == 0 {
+				continue
+			}
+			index, initialized, err := linked.NextInitializedTickWithinFixedDistance(probe, true, 3000)
+			if below < 0 {
+				assert.ErrorIs(t, err, ErrBelowSmallest)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, initialized)
+				assert.Equal(t, ticks[below].Index, index)
+			}
+			index, initialized, err = linked.NextInitializedTickWithinFixedDistance(probe, false, 3000)
+			if above == len(ticks) {
+				assert.ErrorIs(t, err, ErrAtOrAboveLargest)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, initialized)
+				assert.Equal(t, ticks[above].Index, index)
+			}
+		}
+
+		for k := 1; k < len(ticks); k++ {
+			previous, next, err := linked.Neighbours(ticks[k].Index)
+			assert.NoError(t, err)
+			assert.Equal(t, ticks[k-1].Index, previous)
+			if k < len(ticks)-1 {
+				assert.Equal(t, ticks[k+1].Index, next)
+			}
+		}
+	}
+	for i, linked := range copies {
+		assert.Equal(t, len(copiesTicks[i]), len(linked.Ticks()))
+		if len(copiesTicks[i]) > 0 {
+			assert.Equal(t, copiesTicks[i], linked.Ticks())
+		}
+	}
+
+	// a provider that updated copies were made of can't be modified
+	assert.ErrorIs(t, copies[0].Insert(Tick{Index: 2000, LiquidityGross: OneEther, LiquidityNet: OneEther}), ErrTickDataProviderShared)
+	assert.ErrorIs(t, copies[0].Delete(copiesTicks[0][0].Index), ErrTickDataProviderShared)
+}
+
+func TestLinkedListTickDataProvider_SparseTicks(t *testing.T) {
+	// the ticks are far apart, the next initialized tick is found by walking the list
+	ticks := []Tick{
+		{Index: NearestUsableTick(utils.MinTick, 1), LiquidityGross: OneEther, LiquidityNet: OneEther},
+		{Index: 0, LiquidityGross: OneEther, LiquidityNet: OneEther},
+		{Index: NearestUsableTick(utils.MaxTick, 1), LiquidityGross: OneEther, LiquidityNet: new(big.Int).Mul(OneEther, big.NewInt(-2))},
+	}
+	linked, err := NewLinkedListTickDataProvider(ticks, 1)
+	assert.NoError(t, err)
+
+	tick, err := linked.GetTick(-1)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.MinTick, tick.Index)
+	index, initialized, err := linked.NextInitializedTickWithinFixedDistance(-1, false, 1000000)
+	assert.NoError(t, err)
+	assert.True(t, initialized)
+	assert.Equal(t, 0, index)
+	index, initialized, err = linked.NextInitializedTickWithinFixedDistance(1, false, 1000)
+	assert.NoError(t, err)
+	assert.False(t, initialized)
+	assert.Equal(t, 1001, index)
+	index, initialized, err = linked.NextInitializedTickWithinFixedDistance(utils.MaxTick-1, true, 2*utils.MaxTick)
+	assert.NoError(t, err)
+	assert.True(t, initialized)
+	assert.Equal(t, 0, index)
+}
+
+func TestLinkedListTickDataProvider_ManySparseTicks(t *testing.T) {
+	// the ticks are far apart and more numerous than a walk of the list could afford for every lookup
+	ticks := make([]Tick, 40000)
+	for i := range ticks {
+		ticks[i] = Tick{Index: (i - len(ticks)/2) * 20, LiquidityGross: OneEther, LiquidityNet: big.NewInt(0)}
+	}
+	linked, err := NewLinkedListTickDataProvider(ticks, 10)
+	assert.NoError(t, err)
+	updated, err := linked.UpdateTicks([]Tick{
+		{Index: 10, LiquidityGross: OneEther, LiquidityNet: big.NewInt(0)},
+		{Index: 20, LiquidityGross: big.NewInt(0), LiquidityNet: big.NewInt(0)},
+	})
+	assert.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		tick, err := updated.GetTick(ticks[i*40].Index + 5)
+		assert.NoError(t, err)
+		assert.Equal(t, ticks[i*40].Index, tick.Index)
+	}
+	assert.Less(t, time.Since(start), time.Second)
+
+	tick, err := updated.GetTick(25)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, tick.Index, "the deleted tick is skipped")
+	index, initialized, err := updated.NextInitializedTickWithinFixedDistance(10, false, 100)
+	assert.NoError(t, err)
+	assert.True(t, initialized)
+	assert.Equal(t, 40, index)
+}
@@ -17,8 +17,45 @@ var (
 	ErrTokenNotInvolved         = errors.New("Token not involved in pool")
 	ErrSqrtPriceLimitX96TooLow  = errors.New("SqrtPriceLimitX96 too low")
 	ErrSqrtPriceLimitX96TooHigh = errors.New("SqrtPriceLimitX96 too high")
+
+	ErrInvalidLiquidity             = errors.New("invalid liquidity")
+	ErrInsufficientTickLiquidity    = errors.New("insufficient tick liquidity")
+	ErrTickDataProviderNotUpdatable = errors.New("tick data provider is not updatable")
+	ErrInvalidRTokenQty             = errors.New("invalid rToken quantity")
+	ErrReinvestmentStateUnknown     = errors.New("reinvestment state unknown")
+	ErrInvalidTickDistance          = errors.New("invalid tick distance")
 )
 
+// InsufficientLiquidityError is returned when a swap can only be partially filled, either because there is no
+// initialized tick left to swap through or because the price reached MinSqrtRatio or MaxSqrtRatio. A swap stopped by a
+// sqrt price limit given by the caller is not an error, it returns the amounts filled up to the limit
+type InsufficientLiquidityError struct {
+	AmountIn  *entities.CurrencyAmount // The input amount actually filled
+	AmountOut *entities.CurrencyAmount // The output amount actually filled
+
+	err error // The error returned by the tick data provider, if any
+}
+
+func (e *InsufficientLiquidityError) Error() string {
+	if e.err != nil {
+		return "insufficient liquidity: " + e.err.Error()
+	}
+	return "insufficient liquidity"
+}
+
+func (e *InsufficientLiquidityError) Unwrap() error {
+	return e.err
+}
+
+// InsufficientInputAmountError is returned when the input amount of a swap is too low to get any output
+type InsufficientInputAmountError struct {
+	AmountIn *entities.CurrencyAmount // The input amount of the swap
+}
+
+func (e *InsufficientInputAmountError) Error() string {
+	return "insufficient input amount"
+}
+
 type StepComputations struct {
 	sqrtPriceStartX96 *big.Int
 	tickNext          int
@@ -29,6 +66,45 @@ type StepComputations struct {
 	deltaL            *big.Int
 }
 
+// SwapStep describes a single iteration of the swap loop, i.e. the movement of the price from one
+// (possibly uninitialized) tick boundary towards the next one
+type SwapStep struct {
+	SqrtPriceStartX96 *big.Int // The sqrt price at the beginning of the step
+	SqrtPriceEndX96   *big.Int // The sqrt price at the end of the step
+	TickNext          int      // The tick the step was moving towards
+	Initialized       bool     // Whether TickNext is initialized
+	Crossed           bool     // Whether the step reached and crossed TickNext
+	LiquidityBefore   *big.Int // The base liquidity at the beginning of the step
+	LiquidityAfter    *big.Int // The base liquidity at the end of the step, after crossing TickNext if it was crossed
+	AmountIn          *big.Int // The amount of input token consumed by the step, fees included
+	AmountOut         *big.Int // The amount of output token returned by the step
+	DeltaL            *big.Int // The reinvestment liquidity minted from the fees of the step
+}
+
+// swapResult holds the final state of a simulated swap
+type swapResult struct {
+	amountCalculated  *big.Int
+	sqrtRatioX96      *big.Int
+	liquidity         *big.Int
+	reinvestLiquidity *big.Int
+	tickCurrent       int
+	steps             []SwapStep
+
+	// the part of amountSpecified that could not be filled, and the error that stopped the swap early if any
+	amountSpecifiedRemaining *big.Int
+	exhaustedErr             error
+
+	reinvestLiquidityLast *big.Int
+	rTotalSupply          *big.Int
+	rTokenMinted          *big.Int
+
+	feeGrowthGlobal               *big.Int
+	secondsPerLiquidityGlobal     *big.Int
+	secondsPerLiquidityUpdateTime uint32
+	// the crossed ticks, with their outside accumulators flipped
+	crossedTicks []Tick
+}
+
 // Represents a V3 pool
 type Pool struct {
 	Token0            *entities.Token
@@ -40,14 +116,57 @@ type Pool struct {
 	TickCurrent       int
 	TickDataProvider  TickDataProvider
 
+	// The tick spacing of the pool, from the fee tier registry the pool was constructed with
+	TickSpacing int
+	// The maximum number of ticks a single step of a swap can travel, which depends on the deployment of the pool
+	TickDistance int
+
+	// The optional reinvestment state of the pool, required to compute the reinvestment tokens minted by swaps.
+	// ReinvestLiquidityLast is the reinvestment liquidity at the last time rTokens were minted,
+	// RTotalSupply is the total supply of the pool's reinvestment token
+	ReinvestLiquidityLast *big.Int
+	RTotalSupply          *big.Int
+
+	// The optional fee and time accumulators of the pool, required to keep the FeeGrowthOutside and
+	// SecondsPerLiquidityOutside of the ticks up to date. FeeGrowthGlobal is the amount of rTokens earned per unit of
+	// base liquidity, as a Q96 value, and is only tracked along with the reinvestment state. SecondsPerLiquidityGlobal
+	// is the time spent per unit of base liquidity, as a Q96 value, last updated at SecondsPerLiquidityUpdateTime
+	FeeGrowthGlobal               *big.Int
+	SecondsPerLiquidityGlobal     *big.Int
+	SecondsPerLiquidityUpdateTime uint32
+
+	// The share of the minted rTokens that goes to the protocol instead of the LPs, in fee units (1e5 = 100%)
+	GovernmentFeeUnits uint32
+	// The block timestamp at which swaps, mints and burns are simulated, used to update SecondsPerLiquidityGlobal
+	BlockTimestamp uint32
+
 	token0Price *entities.Price
 	token1Price *entities.Price
 }
 
+/**
+ * Computes the address of a pool of the deployment of the chain of the tokens, see constants.GetDeployment. The pools
+ * of chains without a registered deployment are the ones of the default factory
+ * @param tokenA The first token of the pair, irrespective of sort order
+ * @param tokenB The second token of the pair, irrespective of sort order
+ * @param fee The fee tier of the pool
+ * @param initCodeHashManualOverride The init code hash of the pools, empty for the one of the deployment
+ * @returns The pool address
+ */
 func GetAddress(
 	tokenA, tokenB *entities.Token, fee constants.FeeAmount, initCodeHashManualOverride string,
 ) (common.Address, error) {
-	return utils.ComputePoolAddress(constants.FactoryAddress, tokenA, tokenB, fee, initCodeHashManualOverride)
+	factory, initCodeHash := constants.FactoryAddress, constants.PoolInitCodeHash
+	deployment, err := constants.GetDeployment(tokenA.ChainId())
+	if err == nil {
+		factory, initCodeHash = deployment.Factory, deployment.PoolInitCodeHash
+	} else if !errors.Is(err, constants.ErrUnknownChain) {
+		return common.Address{}, err
+	}
+	if initCodeHashManualOverride == "" {
+		initCodeHashManualOverride = initCodeHash
+	}
+	return utils.ComputePoolAddress(factory, tokenA, tokenB, fee, initCodeHashManualOverride)
 }
 
 /**
@@ -64,10 +183,56 @@ func NewPool(
 	tokenA, tokenB *entities.Token, fee constants.FeeAmount, sqrtRatioX96 *big.Int,
 	liquidity, reinvestLiquidity *big.Int, tickCurrent int, ticks TickDataProvider,
 ) (*Pool, error) {
+	return NewPoolWithTickDistance(
+		tokenA, tokenB, fee, sqrtRatioX96, liquidity, reinvestLiquidity, tickCurrent, ticks, constants.MaxTickDistance,
+	)
+}
+
+/**
+ * Construct a pool of a deployment whose swaps travel a different number of ticks per step than the default
+ * constants.MaxTickDistance, e.g. a fork or a newer version of the pool contract
+ * @param tickDistance The maximum number of ticks a single step of a swap can travel, the MAX_TICK_DISTANCE of the pool contract
+ */
+func NewPoolWithTickDistance(
+	tokenA, tokenB *entities.Token, fee constants.FeeAmount, sqrtRatioX96 *big.Int,
+	liquidity, reinvestLiquidity *big.Int, tickCurrent int, ticks TickDataProvider, tickDistance int,
+) (*Pool, error) {
+	return NewPoolWithFeeTiers(
+		tokenA, tokenB, fee, sqrtRatioX96, liquidity, reinvestLiquidity, tickCurrent, ticks, DefaultFeeTiers, tickDistance,
+	)
+}
+
+/**
+ * Construct a pool whose tick spacing is read from a fee tier registry other than DefaultFeeTiers, e.g. one backed by
+ * the factory contract of the deployment
+ * @param feeTiers The registry the tick spacing of the fee tier of the pool is read from
+ * @param tickDistance The maximum number of ticks a single step of a swap can travel, constants.MaxTickDistance by default
+ */
+func NewPoolWithFeeTiers(
+	tokenA, tokenB *entities.Token, fee constants.FeeAmount, sqrtRatioX96 *big.Int,
+	liquidity, reinvestLiquidity *big.Int, tickCurrent int, ticks TickDataProvider, feeTiers FeeTierRegistry,
+	tickDistance int,
+) (*Pool, error) {
+	if tickDistance <= 0 {
+		return nil, ErrInvalidTickDistance
+	}
 	if fee >= constants.FeeMax {
 		return nil, ErrFeeTooHigh
 	}
+	tickSpacing, ok := feeTiers.TickSpacing(fee)
+	if !ok {
+		return nil, ErrUnknownFeeTier
+	}
+	return newPool(
+		tokenA, tokenB, fee, sqrtRatioX96, liquidity, reinvestLiquidity, tickCurrent, ticks, tickSpacing, tickDistance,
+	)
+}
 
+// newPool constructs a pool with the given tick spacing and tick distance, which are validated by its callers
+func newPool(
+	tokenA, tokenB *entities.Token, fee constants.FeeAmount, sqrtRatioX96 *big.Int,
+	liquidity, reinvestLiquidity *big.Int, tickCurrent int, ticks TickDataProvider, tickSpacing, tickDistance int,
+) (*Pool, error) {
 	tickCurrentSqrtRatioX96, err := utils.GetSqrtRatioAtTick(tickCurrent)
 	if err != nil {
 		return nil, err
@@ -100,6 +265,8 @@ func NewPool(
 		ReinvestLiquidity: reinvestLiquidity,
 		TickCurrent:       tickCurrent,
 		TickDataProvider:  ticks,
+		TickSpacing:       tickSpacing,
+		TickDistance:      tickDistance,
 	}, nil
 }
 
@@ -154,6 +321,11 @@ func (p *Pool) ChainID() uint {
 	return p.Token0.ChainId()
 }
 
+// GetAddress returns the address of the pool in the deployment of its chain, see constants.GetDeployment
+func (p *Pool) GetAddress() (common.Address, error) {
+	return GetAddress(p.Token0, p.Token1, p.Fee, "")
+}
+
 /**
  * Given an input amount of a token, return the computed output amount, and a pool with state updated after the trade
  * @param inputAmount The input amount for which to quote the output amount
@@ -163,15 +335,39 @@ func (p *Pool) ChainID() uint {
 func (p *Pool) GetOutputAmount(
 	inputAmount *entities.CurrencyAmount, sqrtPriceLimitX96 *big.Int,
 ) (*entities.CurrencyAmount, *Pool, error) {
+	outputAmount, pool, _, err := p.getOutputAmount(inputAmount, sqrtPriceLimitX96)
+	if err != nil {
+		return nil, nil, err
+	}
+	return outputAmount, pool, nil
+}
+
+/**
+ * Same as GetOutputAmount, but also returns every step the swap went through, in order
+ * @param inputAmount The input amount for which to quote the output amount
+ * @param sqrtPriceLimitX96 The Q64.96 sqrt price limit
+ * @returns The output amount, the pool with updated state and the swap steps
+ */
+func (p *Pool) GetOutputAmountWithTrace(
+	inputAmount *entities.CurrencyAmount, sqrtPriceLimitX96 *big.Int,
+) (*entities.CurrencyAmount, *Pool, []SwapStep, error) {
+	outputAmount, pool, result, err := p.getOutputAmount(inputAmount, sqrtPriceLimitX96)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return outputAmount, pool, result.steps, nil
+}
+
+func (p *Pool) getOutputAmount(
+	inputAmount *entities.CurrencyAmount, sqrtPriceLimitX96 *big.Int,
+) (*entities.CurrencyAmount, *Pool, *swapResult, error) {
 	if !(inputAmount.Currency.IsToken() && p.InvolvesToken(inputAmount.Currency.Wrapped())) {
-		return nil, nil, ErrTokenNotInvolved
+		return nil, nil, nil, ErrTokenNotInvolved
 	}
 	zeroForOne := inputAmount.Currency.Equal(p.Token0)
-	outputAmount, sqrtRatioX96, liquidity, reinvestLiquidity, tickCurrent, err := p.swap(
-		zeroForOne, inputAmount.Quotient(), sqrtPriceLimitX96,
-	)
+	result, err := p.swap(zeroForOne, inputAmount.Quotient(), sqrtPriceLimitX96)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	var outputToken *entities.Token
 	if zeroForOne {
@@ -179,15 +375,26 @@ func (p *Pool) GetOutputAmount(
 	} else {
 		outputToken = p.Token0
 	}
-	pool, err := NewPool(
-		p.Token0, p.Token1, p.Fee, sqrtRatioX96, liquidity, reinvestLiquidity, tickCurrent, p.TickDataProvider,
-	)
+	outputAmount := entities.FromRawAmount(outputToken, new(big.Int).Mul(result.amountCalculated, constants.NegativeOne))
+
+	// the pool after the swap is only built for filled swaps, as updating its ticks can fetch them
+	if result.partiallyFilled(sqrtPriceLimitX96) {
+		return nil, nil, nil, &InsufficientLiquidityError{
+			AmountIn: entities.FromRawAmount(
+				inputAmount.Currency, new(big.Int).Sub(inputAmount.Quotient(), result.amountSpecifiedRemaining),
+			),
+			AmountOut: outputAmount,
+			err:       result.exhaustedErr,
+		}
+	}
+	if outputAmount.Quotient().Cmp(constants.Zero) == 0 {
+		return nil, nil, nil, &InsufficientInputAmountError{AmountIn: inputAmount}
+	}
+	pool, err := p.afterSwap(result)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	return entities.FromRawAmount(
-		outputToken, new(big.Int).Mul(outputAmount, constants.NegativeOne),
-	), pool, nil
+	return outputAmount, pool, result, nil
 }
 
 /**
@@ -199,15 +406,41 @@ func (p *Pool) GetOutputAmount(
 func (p *Pool) GetInputAmount(
 	outputAmount *entities.CurrencyAmount, sqrtPriceLimitX96 *big.Int,
 ) (*entities.CurrencyAmount, *Pool, error) {
+	inputAmount, pool, _, err := p.getInputAmount(outputAmount, sqrtPriceLimitX96)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inputAmount, pool, nil
+}
+
+/**
+ * Same as GetInputAmount, but also returns every step the swap went through, in order
+ * @param outputAmount the output amount for which to quote the input amount
+ * @param sqrtPriceLimitX96 The Q64.96 sqrt price limit
+ * @returns The input amount, the pool with updated state and the swap steps
+ */
+func (p *Pool) GetInputAmountWithTrace(
+	outputAmount *entities.CurrencyAmount, sqrtPriceLimitX96 *big.Int,
+) (*entities.CurrencyAmount, *Pool, []SwapStep, error) {
+	inputAmount, pool, result, err := p.getInputAmount(outputAmount, sqrtPriceLimitX96)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return inputAmount, pool, result.steps, nil
+}
+
+func (p *Pool) getInputAmount(
+	outputAmount *entities.CurrencyAmount, sqrtPriceLimitX96 *big.Int,
+) (*entities.CurrencyAmount, *Pool, *swapResult, error) {
 	if !(outputAmount.Currency.IsToken() && p.InvolvesToken(outputAmount.Currency.Wrapped())) {
-		return nil, nil, ErrTokenNotInvolved
+		return nil, nil, nil, ErrTokenNotInvolved
 	}
 	zeroForOne := outputAmount.Currency.Equal(p.Token1)
-	inputAmount, sqrtRatioX96, liquidity, reinvestLiquidity, tickCurrent, err := p.swap(
+	result, err := p.swap(
 		zeroForOne, new(big.Int).Mul(outputAmount.Quotient(), constants.NegativeOne), sqrtPriceLimitX96,
 	)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	var inputToken *entities.Token
 	if zeroForOne {
@@ -215,13 +448,85 @@ func (p *Pool) GetInputAmount(
 	} else {
 		inputToken = p.Token1
 	}
-	pool, err := NewPool(
-		p.Token0, p.Token1, p.Fee, sqrtRatioX96, liquidity, reinvestLiquidity, tickCurrent, p.TickDataProvider,
+	inputAmount := entities.FromRawAmount(inputToken, result.amountCalculated)
+
+	// the pool after the swap is only built for filled swaps, as updating its ticks can fetch them
+	if result.partiallyFilled(sqrtPriceLimitX96) {
+		return nil, nil, nil, &InsufficientLiquidityError{
+			AmountIn: inputAmount,
+			AmountOut: entities.FromRawAmount(
+				outputAmount.Currency, new(big.Int).Add(outputAmount.Quotient(), result.amountSpecifiedRemaining),
+			),
+			err: result.exhaustedErr,
+		}
+	}
+	pool, err := p.afterSwap(result)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return inputAmount, pool, result, nil
+}
+
+// partiallyFilled returns whether the swap stopped before filling the specified amount, either because the initialized
+// ticks ran out or because it reached the default price limit. Swaps stopped by a price limit given by the caller are
+// filled up to the limit, as requested
+func (r *swapResult) partiallyFilled(sqrtPriceLimitX96 *big.Int) bool {
+	if r.amountSpecifiedRemaining.Sign() == 0 {
+		return false
+	}
+	return sqrtPriceLimitX96 == nil || r.exhaustedErr != nil
+}
+
+// afterSwap returns a new pool with the state updated to the final state of the swap
+func (p *Pool) afterSwap(result *swapResult) (*Pool, error) {
+	pool, err := p.WithState(
+		result.sqrtRatioX96, result.liquidity, result.reinvestLiquidity, result.tickCurrent, p.TickDataProvider,
 	)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	pool.ReinvestLiquidityLast, pool.RTotalSupply = result.reinvestLiquidityLast, result.rTotalSupply
+	pool.FeeGrowthGlobal = result.feeGrowthGlobal
+	pool.SecondsPerLiquidityGlobal = result.secondsPerLiquidityGlobal
+	pool.SecondsPerLiquidityUpdateTime = result.secondsPerLiquidityUpdateTime
+
+	// the accumulators of the crossed ticks can only be updated on a provider that can be updated
+	if provider, ok := p.TickDataProvider.(UpdatableTickDataProvider); ok && len(result.crossedTicks) > 0 {
+		pool.TickDataProvider, err = provider.UpdateTicks(result.crossedTicks)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pool, nil
+}
+
+/**
+ * Returns a copy of the pool with its price, liquidity, tick and ticks replaced. The tick spacing, the tick distance
+ * and the optional state of the pool are kept, the fee tier registry is not read again
+ * @param sqrtRatioX96 The sqrt of the new ratio of amounts of token1 to token0
+ * @param liquidity The new in range liquidity
+ * @param reinvestLiquidity The new reinvestment liquidity
+ * @param tickCurrent The new tick of the pool
+ * @param ticks The new tick data provider
+ * @returns The new pool, the pool itself is not modified
+ */
+func (p *Pool) WithState(
+	sqrtRatioX96, liquidity, reinvestLiquidity *big.Int, tickCurrent int, ticks TickDataProvider,
+) (*Pool, error) {
+	pool, err := newPool(
+		p.Token0, p.Token1, p.Fee, sqrtRatioX96, liquidity, reinvestLiquidity, tickCurrent, ticks,
+		p.tickSpacing(), p.tickDistance(),
+	)
+	if err != nil {
+		return nil, err
 	}
-	return entities.FromRawAmount(inputToken, inputAmount), pool, nil
+	pool.ReinvestLiquidityLast, pool.RTotalSupply = p.ReinvestLiquidityLast, p.RTotalSupply
+	pool.FeeGrowthGlobal = p.FeeGrowthGlobal
+	pool.SecondsPerLiquidityGlobal = p.SecondsPerLiquidityGlobal
+	pool.SecondsPerLiquidityUpdateTime = p.SecondsPerLiquidityUpdateTime
+	pool.GovernmentFeeUnits = p.GovernmentFeeUnits
+	pool.BlockTimestamp = p.BlockTimestamp
+	return pool, nil
 }
 
 /**
@@ -229,14 +534,9 @@ func (p *Pool) GetInputAmount(
  * @param zeroForOne Whether the amount in is token0 or token1
  * @param amountSpecified The amount of the swap, which implicitly configures the swap as exact input (positive), or exact output (negative)
  * @param sqrtPriceLimitX96 The Q64.96 sqrt price limit. If zero for one, the price cannot be less than this value after the swap. If one for zero, the price cannot be greater than this value after the swap
- * @returns amountCalculated
- * @returns sqrtRatioX96
- * @returns liquidity
- * @returns tickCurrent
+ * @returns The final state of the swap, including every step it went through
  */
-func (p *Pool) swap(zeroForOne bool, amountSpecified, sqrtPriceLimitX96 *big.Int) (
-	amountCalCulated *big.Int, sqrtRatioX96 *big.Int, liquidity, reinvestLiquidity *big.Int, tickCurrent int, err error,
-) {
+func (p *Pool) swap(zeroForOne bool, amountSpecified, sqrtPriceLimitX96 *big.Int) (*swapResult, error) {
 	if sqrtPriceLimitX96 == nil {
 		if zeroForOne {
 			sqrtPriceLimitX96 = new(big.Int).Add(utils.MinSqrtRatio, constants.One)
@@ -247,22 +547,27 @@ func (p *Pool) swap(zeroForOne bool, amountSpecified, sqrtPriceLimitX96 *big.Int
 
 	if zeroForOne {
 		if sqrtPriceLimitX96.Cmp(utils.MinSqrtRatio) < 0 {
-			return nil, nil, nil, nil, 0, ErrSqrtPriceLimitX96TooLow
+			return nil, ErrSqrtPriceLimitX96TooLow
 		}
 		if sqrtPriceLimitX96.Cmp(p.SqrtRatioX96) >= 0 {
-			return nil, nil, nil, nil, 0, ErrSqrtPriceLimitX96TooHigh
+			return nil, ErrSqrtPriceLimitX96TooHigh
 		}
 	} else {
 		if sqrtPriceLimitX96.Cmp(utils.MaxSqrtRatio) > 0 {
-			return nil, nil, nil, nil, 0, ErrSqrtPriceLimitX96TooHigh
+			return nil, ErrSqrtPriceLimitX96TooHigh
 		}
 		if sqrtPriceLimitX96.Cmp(p.SqrtRatioX96) <= 0 {
-			return nil, nil, nil, nil, 0, ErrSqrtPriceLimitX96TooLow
+			return nil, ErrSqrtPriceLimitX96TooLow
 		}
 	}
 
 	exactInput := amountSpecified.Cmp(constants.Zero) >= 0
 
+	var (
+		steps        []SwapStep
+		exhaustedErr error
+	)
+
 	// keep track of swap state
 
 	state := struct {
@@ -272,6 +577,14 @@ func (p *Pool) swap(zeroForOne bool, amountSpecified, sqrtPriceLimitX96 *big.Int
 		tick                     int
 		liquidity                *big.Int
 		reinvestLiquidity        *big.Int
+		reinvestLiquidityLast    *big.Int
+		rTotalSupply             *big.Int
+		rTokenMinted             *big.Int
+
+		feeGrowthGlobal               *big.Int
+		secondsPerLiquidityGlobal     *big.Int
+		secondsPerLiquidityUpdateTime uint32
+		crossedTicks                  []Tick
 	}{
 		amountSpecifiedRemaining: amountSpecified,
 		amountCalculated:         constants.Zero,
@@ -279,7 +592,20 @@ func (p *Pool) swap(zeroForOne bool, amountSpecified, sqrtPriceLimitX96 *big.Int
 		tick:                     p.TickCurrent,
 		liquidity:                p.Liquidity,
 		reinvestLiquidity:        p.ReinvestLiquidity,
+		reinvestLiquidityLast:    p.ReinvestLiquidityLast,
+		rTotalSupply:             p.RTotalSupply,
+
+		feeGrowthGlobal:               p.FeeGrowthGlobal,
+		secondsPerLiquidityGlobal:     p.SecondsPerLiquidityGlobal,
+		secondsPerLiquidityUpdateTime: p.SecondsPerLiquidityUpdateTime,
+	}
+	trackRTokens := p.ReinvestLiquidityLast != nil && p.RTotalSupply != nil
+	if trackRTokens {
+		state.rTokenMinted = big.NewInt(0)
 	}
+	trackFeeGrowth := trackRTokens && p.FeeGrowthGlobal != nil
+	// the accumulators are only loaded, and the seconds per liquidity synced, when the first tick is crossed
+	crossedInitializedTick := false
 
 	// start swap while loop
 	for state.amountSpecifiedRemaining.Cmp(constants.Zero) != 0 && state.sqrtPriceX96.Cmp(sqrtPriceLimitX96) != 0 {
@@ -289,11 +615,17 @@ func (p *Pool) swap(zeroForOne bool, amountSpecified, sqrtPriceLimitX96 *big.Int
 		// because each iteration of the while loop rounds, we can't optimize this code (relative to the smart contract)
 		// by simply traversing to the next available tick, we instead need to exactly replicate
 		// tickBitmap.nextInitializedTickWithinOneWord
+		var err error
 		step.tickNext, step.initialized, err = p.TickDataProvider.NextInitializedTickWithinFixedDistance(
-			state.tick, zeroForOne, 480,
+			state.tick, zeroForOne, p.tickDistance(),
 		)
+		if errors.Is(err, ErrBelowSmallest) || errors.Is(err, ErrAtOrAboveLargest) {
+			// no initialized tick left in the swap direction, the swap can only be partially filled
+			exhaustedErr = err
+			break
+		}
 		if err != nil {
-			return nil, nil, nil, nil, 0, err
+			return nil, err
 		}
 
 		if step.tickNext < utils.MinTick {
@@ -304,7 +636,7 @@ func (p *Pool) swap(zeroForOne bool, amountSpecified, sqrtPriceLimitX96 *big.Int
 
 		step.sqrtPriceNextX96, err = utils.GetSqrtRatioAtTick(step.tickNext)
 		if err != nil {
-			return nil, nil, nil, nil, 0, err
+			return nil, err
 		}
 		var targetValue *big.Int
 		if zeroForOne {
@@ -326,20 +658,52 @@ func (p *Pool) swap(zeroForOne bool, amountSpecified, sqrtPriceLimitX96 *big.Int
 			state.amountSpecifiedRemaining, p.Fee, exactInput, zeroForOne,
 		)
 		if err != nil {
-			return nil, nil, nil, nil, 0, err
+			return nil, err
 		}
 
 		state.amountSpecifiedRemaining = new(big.Int).Sub(state.amountSpecifiedRemaining, step.amountIn)
 		state.amountCalculated = new(big.Int).Add(state.amountCalculated, step.amountOut)
 		state.reinvestLiquidity = new(big.Int).Add(state.reinvestLiquidity, step.deltaL)
 
+		liquidityBefore := state.liquidity
+		crossed := state.sqrtPriceX96.Cmp(step.sqrtPriceNextX96) == 0
+
 		// TODO
-		if state.sqrtPriceX96.Cmp(step.sqrtPriceNextX96) == 0 {
+		if crossed {
 			// if the tick is initialized, run the tick transition
 			if step.initialized {
+				if !crossedInitializedTick && state.secondsPerLiquidityGlobal != nil {
+					state.secondsPerLiquidityGlobal, state.secondsPerLiquidityUpdateTime = p.syncSecondsPerLiquidity(
+						state.secondsPerLiquidityGlobal, state.liquidity,
+					)
+				}
+				crossedInitializedTick = true
+
+				// mint the reinvestment tokens for the fees accumulated so far, before the base liquidity changes
+				if trackRTokens {
+					rMintQty := utils.CalcRMintQty(
+						state.reinvestLiquidity, state.reinvestLiquidityLast, state.liquidity, state.rTotalSupply,
+					)
+					state.rTotalSupply = new(big.Int).Add(state.rTotalSupply, rMintQty)
+					state.rTokenMinted = new(big.Int).Add(state.rTokenMinted, rMintQty)
+					state.reinvestLiquidityLast = state.reinvestLiquidity
+					if trackFeeGrowth {
+						state.feeGrowthGlobal = p.growFeeGrowthGlobal(state.feeGrowthGlobal, rMintQty, state.liquidity)
+					}
+				}
+
 				tick, err := p.TickDataProvider.GetTick(step.tickNext)
 				if err != nil {
-					return nil, nil, nil, nil, 0, err
+					return nil, err
+				}
+				if !trackFeeGrowth {
+					// the fee growth can't be followed without the reinvestment state
+					state.feeGrowthGlobal = nil
+				}
+				if state.feeGrowthGlobal != nil || state.secondsPerLiquidityGlobal != nil {
+					state.crossedTicks = append(
+						state.crossedTicks, crossTick(tick, state.feeGrowthGlobal, state.secondsPerLiquidityGlobal),
+					)
 				}
 
 				liquidityNet := tick.LiquidityNet
@@ -359,13 +723,297 @@ func (p *Pool) swap(zeroForOne bool, amountSpecified, sqrtPriceLimitX96 *big.Int
 			// recompute unless we're on a lower tick boundary (i.e. already transitioned ticks), and haven't moved
 			state.tick, err = utils.GetTickAtSqrtRatio(state.sqrtPriceX96)
 			if err != nil {
-				return nil, nil, nil, nil, 0, err
+				return nil, err
+			}
+		}
+
+		// amounts of the step are signed the same way as amountSpecified and amountCalculated,
+		// normalize them to the absolute amounts of input and output token
+		amountIn, amountOut := step.amountIn, new(big.Int).Neg(step.amountOut)
+		if !exactInput {
+			amountIn, amountOut = step.amountOut, new(big.Int).Neg(step.amountIn)
+		}
+		steps = append(steps, SwapStep{
+			SqrtPriceStartX96: step.sqrtPriceStartX96,
+			SqrtPriceEndX96:   state.sqrtPriceX96,
+			TickNext:          step.tickNext,
+			Initialized:       step.initialized,
+			Crossed:           crossed,
+			LiquidityBefore:   liquidityBefore,
+			LiquidityAfter:    state.liquidity,
+			AmountIn:          new(big.Int).Set(amountIn),
+			AmountOut:         amountOut,
+			DeltaL:            new(big.Int).Set(step.deltaL),
+		})
+	}
+	return &swapResult{
+		amountCalculated:  state.amountCalculated,
+		sqrtRatioX96:      state.sqrtPriceX96,
+		liquidity:         state.liquidity,
+		reinvestLiquidity: state.reinvestLiquidity,
+		tickCurrent:       state.tick,
+		steps:             steps,
+
+		amountSpecifiedRemaining: state.amountSpecifiedRemaining,
+		exhaustedErr:             exhaustedErr,
+
+		reinvestLiquidityLast: state.reinvestLiquidityLast,
+		rTotalSupply:          state.rTotalSupply,
+		rTokenMinted:          state.rTokenMinted,
+
+		feeGrowthGlobal:               state.feeGrowthGlobal,
+		secondsPerLiquidityGlobal:     state.secondsPerLiquidityGlobal,
+		secondsPerLiquidityUpdateTime: state.secondsPerLiquidityUpdateTime,
+		crossedTicks:                  state.crossedTicks,
+	}, nil
+}
+
+/**
+ * Adds liquidity to the pool, as the pool contract would do when a position is minted
+ * @param tickLower The lower tick of the position
+ * @param tickUpper The upper tick of the position
+ * @param liquidity The amount of liquidity to add
+ * @returns A new pool with updated liquidity and tick data, the pool itself is not modified
+ */
+func (p *Pool) Mint(tickLower, tickUpper int, liquidity *big.Int) (*Pool, error) {
+	return p.updatePosition(tickLower, tickUpper, liquidity)
+}
+
+/**
+ * Removes liquidity from the pool, as the pool contract would do when a position is burnt
+ * @param tickLower The lower tick of the position
+ * @param tickUpper The upper tick of the position
+ * @param liquidity The amount of liquidity to remove
+ * @returns A new pool with updated liquidity and tick data, the pool itself is not modified
+ */
+func (p *Pool) Burn(tickLower, tickUpper int, liquidity *big.Int) (*Pool, error) {
+	if liquidity == nil {
+		return nil, ErrInvalidLiquidity
+	}
+	return p.updatePosition(tickLower, tickUpper, new(big.Int).Neg(liquidity))
+}
+
+/**
+ * Burns reinvestment tokens, as the pool contract would do when the fees of a position are collected. The pool must
+ * have its reinvestment state set
+ * @param qty The amount of reinvestment tokens to burn
+ * @param isLogicalBurn Whether the tokens are only burnt, without removing their share of the reinvestment liquidity
+ * @returns A new pool with updated reinvestment liquidity and supply, the pool itself is not modified
+ */
+func (p *Pool) BurnRTokens(qty *big.Int, isLogicalBurn bool) (*Pool, error) {
+	if qty == nil || qty.Sign() <= 0 {
+		return nil, ErrInvalidRTokenQty
+	}
+	if p.ReinvestLiquidityLast == nil || p.RTotalSupply == nil {
+		return nil, ErrReinvestmentStateUnknown
+	}
+
+	reinvestLiquidity, reinvestLiquidityLast := p.ReinvestLiquidity, p.ReinvestLiquidityLast
+	rTotalSupply, feeGrowthGlobal := p.RTotalSupply, p.FeeGrowthGlobal
+	if !isLogicalBurn {
+		// the pool mints the reinvestment tokens for the pending fees first, the burnt tokens are then worth their
+		// share of the reinvestment liquidity
+		rMintQty := utils.CalcRMintQty(reinvestLiquidity, reinvestLiquidityLast, p.Liquidity, rTotalSupply)
+		rTotalSupply = new(big.Int).Add(rTotalSupply, rMintQty)
+		if feeGrowthGlobal != nil {
+			feeGrowthGlobal = p.growFeeGrowthGlobal(feeGrowthGlobal, rMintQty, p.Liquidity)
+		}
+	}
+	if qty.Cmp(rTotalSupply) > 0 {
+		return nil, ErrInvalidRTokenQty
+	}
+	if !isLogicalBurn {
+		reinvestLiquidityDelta := utils.MulDivRoundingDown(qty, reinvestLiquidity, rTotalSupply)
+		reinvestLiquidity = new(big.Int).Sub(reinvestLiquidity, reinvestLiquidityDelta)
+		reinvestLiquidityLast = reinvestLiquidity
+	}
+	rTotalSupply = new(big.Int).Sub(rTotalSupply, qty)
+
+	pool, err := p.WithState(p.SqrtRatioX96, p.Liquidity, reinvestLiquidity, p.TickCurrent, p.TickDataProvider)
+	if err != nil {
+		return nil, err
+	}
+	pool.ReinvestLiquidityLast, pool.RTotalSupply, pool.FeeGrowthGlobal = reinvestLiquidityLast, rTotalSupply, feeGrowthGlobal
+	return pool, nil
+}
+
+func (p *Pool) updatePosition(tickLower, tickUpper int, liquidityDelta *big.Int) (*Pool, error) {
+	if liquidityDelta == nil || liquidityDelta.Cmp(constants.Zero) == 0 {
+		return nil, ErrInvalidLiquidity
+	}
+	if tickLower >= tickUpper {
+		return nil, ErrTickOrder
+	}
+	if p.tickSpacing() <= 0 {
+		return nil, ErrUnknownFeeTier
+	}
+	if tickLower < utils.MinTick || tickLower%p.tickSpacing() != 0 {
+		return nil, ErrTickLower
+	}
+	if tickUpper > utils.MaxTick || tickUpper%p.tickSpacing() != 0 {
+		return nil, ErrTickUpper
+	}
+
+	var provider UpdatableTickDataProvider
+	if p.TickDataProvider == nil {
+		provider = &TickListDataProvider{}
+	} else {
+		var ok bool
+		if provider, ok = p.TickDataProvider.(UpdatableTickDataProvider); !ok {
+			return nil, ErrTickDataProviderNotUpdatable
+		}
+	}
+
+	liquidity := p.Liquidity
+	reinvestLiquidityLast, rTotalSupply := p.ReinvestLiquidityLast, p.RTotalSupply
+	feeGrowthGlobal, secondsPerLiquidityGlobal := p.FeeGrowthGlobal, p.SecondsPerLiquidityGlobal
+	secondsPerLiquidityUpdateTime := p.SecondsPerLiquidityUpdateTime
+	// if the current tick is within the position range, the base liquidity changes
+	inRange := tickLower <= p.TickCurrent && p.TickCurrent < tickUpper
+	if inRange {
+		// the pool mints the reinvestment tokens for the fees accumulated so far before the base liquidity changes
+		if reinvestLiquidityLast != nil && rTotalSupply != nil {
+			rMintQty := utils.CalcRMintQty(p.ReinvestLiquidity, reinvestLiquidityLast, liquidity, rTotalSupply)
+			rTotalSupply = new(big.Int).Add(rTotalSupply, rMintQty)
+			reinvestLiquidityLast = p.ReinvestLiquidity
+			if feeGrowthGlobal != nil {
+				feeGrowthGlobal = p.growFeeGrowthGlobal(feeGrowthGlobal, rMintQty, liquidity)
 			}
+		} else {
+			// the fee growth can't be followed without the reinvestment state
+			feeGrowthGlobal = nil
+		}
+		if secondsPerLiquidityGlobal != nil {
+			secondsPerLiquidityGlobal, secondsPerLiquidityUpdateTime = p.syncSecondsPerLiquidity(
+				secondsPerLiquidityGlobal, liquidity,
+			)
 		}
+
+		liquidity = new(big.Int).Add(liquidity, liquidityDelta)
+		if liquidity.Cmp(constants.Zero) < 0 {
+			return nil, ErrInsufficientTickLiquidity
+		}
+	}
+
+	lower, err := p.updatedTick(provider, tickLower, liquidityDelta, false, feeGrowthGlobal, secondsPerLiquidityGlobal)
+	if err != nil {
+		return nil, err
 	}
-	return state.amountCalculated, state.sqrtPriceX96, state.liquidity, state.reinvestLiquidity, state.tick, nil
+	upper, err := p.updatedTick(provider, tickUpper, liquidityDelta, true, feeGrowthGlobal, secondsPerLiquidityGlobal)
+	if err != nil {
+		return nil, err
+	}
+	ticks, err := provider.UpdateTicks([]Tick{lower, upper})
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := p.WithState(p.SqrtRatioX96, liquidity, p.ReinvestLiquidity, p.TickCurrent, ticks)
+	if err != nil {
+		return nil, err
+	}
+	pool.ReinvestLiquidityLast, pool.RTotalSupply = reinvestLiquidityLast, rTotalSupply
+	pool.FeeGrowthGlobal, pool.SecondsPerLiquidityGlobal = feeGrowthGlobal, secondsPerLiquidityGlobal
+	pool.SecondsPerLiquidityUpdateTime = secondsPerLiquidityUpdateTime
+	return pool, nil
 }
 
+/**
+ * Returns the state of a tick after adding liquidityDelta to a position starting or ending at it
+ * @param feeGrowthGlobal The fee growth of the pool, nil if unknown
+ * @param secondsPerLiquidityGlobal The seconds per liquidity of the pool, nil if unknown
+ */
+func (p *Pool) updatedTick(
+	provider TickDataProvider, index int, liquidityDelta *big.Int, upper bool,
+	feeGrowthGlobal, secondsPerLiquidityGlobal *big.Int,
+) (Tick, error) {
+	tick, err := provider.GetTick(index)
+	if err != nil && !errors.Is(err, ErrEmptyTickList) && !errors.Is(err, ErrBelowSmallest) {
+		return EmptyTick, err
+	}
+	updated := Tick{Index: index, LiquidityGross: big.NewInt(0), LiquidityNet: big.NewInt(0)}
+	if err == nil && tick.Index == index {
+		// the tick is initialized
+		updated.LiquidityGross.Set(tick.LiquidityGross)
+		updated.LiquidityNet.Set(tick.LiquidityNet)
+		updated.FeeGrowthOutside, updated.SecondsPerLiquidityOutside = tick.FeeGrowthOutside, tick.SecondsPerLiquidityOutside
+	} else if index <= p.TickCurrent {
+		// by convention, all growth before a tick was initialized is assumed to happen below it
+		updated.FeeGrowthOutside, updated.SecondsPerLiquidityOutside = feeGrowthGlobal, secondsPerLiquidityGlobal
+	} else if feeGrowthGlobal != nil || secondsPerLiquidityGlobal != nil {
+		updated.FeeGrowthOutside, updated.SecondsPerLiquidityOutside = big.NewInt(0), big.NewInt(0)
+	}
+
+	updated.LiquidityGross.Add(updated.LiquidityGross, liquidityDelta)
+	if updated.LiquidityGross.Cmp(constants.Zero) < 0 {
+		return EmptyTick, ErrInsufficientTickLiquidity
+	}
+	if upper {
+		updated.LiquidityNet.Sub(updated.LiquidityNet, liquidityDelta)
+	} else {
+		updated.LiquidityNet.Add(updated.LiquidityNet, liquidityDelta)
+	}
+	return updated, nil
+}
+
+// tickDistance returns the tick distance of the pool, or the default one for pools that were not built by NewPool
+func (p *Pool) tickDistance() int {
+	if p.TickDistance <= 0 {
+		return constants.MaxTickDistance
+	}
+	return p.TickDistance
+}
+
+// tickSpacing returns the tick spacing of the pool, or the default one of its fee for pools that were not built by NewPool
 func (p *Pool) tickSpacing() int {
-	return constants.TickSpacings[p.Fee]
+	if p.TickSpacing <= 0 {
+		return constants.TickSpacings[p.Fee]
+	}
+	return p.TickSpacing
+}
+
+var (
+	maxUint128 = new(big.Int).Sub(new(big.Int).Lsh(constants.One, 128), constants.One)
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(constants.One, 256), constants.One)
+)
+
+// growFeeGrowthGlobal adds the LP share of newly minted rTokens to the fee growth, wrapping around like an uint256
+func (p *Pool) growFeeGrowthGlobal(feeGrowthGlobal, rMintQty, baseL *big.Int) *big.Int {
+	if rMintQty.Sign() == 0 {
+		return feeGrowthGlobal
+	}
+	governmentFee := new(big.Int).Mul(rMintQty, new(big.Int).SetUint64(uint64(p.GovernmentFeeUnits)))
+	governmentFee.Div(governmentFee, constants.FeeUnits)
+	lpFee := new(big.Int).Sub(rMintQty, governmentFee)
+	// baseL can't be 0 here since no rTokens are minted without base liquidity
+	growth := utils.MulDivRoundingDown(lpFee, constants.Q96, baseL)
+	return growth.Add(growth, feeGrowthGlobal).And(growth, maxUint256)
+}
+
+// syncSecondsPerLiquidity returns the seconds per liquidity and its update time at the block timestamp of the pool
+func (p *Pool) syncSecondsPerLiquidity(secondsPerLiquidityGlobal, baseL *big.Int) (*big.Int, uint32) {
+	if p.BlockTimestamp <= p.SecondsPerLiquidityUpdateTime {
+		return secondsPerLiquidityGlobal, p.SecondsPerLiquidityUpdateTime
+	}
+	if baseL.Sign() > 0 {
+		secondsElapsed := big.NewInt(int64(p.BlockTimestamp - p.SecondsPerLiquidityUpdateTime))
+		growth := new(big.Int).Lsh(secondsElapsed, 96)
+		growth.Div(growth, baseL)
+		secondsPerLiquidityGlobal = growth.Add(growth, secondsPerLiquidityGlobal).And(growth, maxUint128)
+	}
+	return secondsPerLiquidityGlobal, p.BlockTimestamp
+}
+
+// crossTick returns the tick with its outside accumulators flipped to the other side, as the tick is crossed
+func crossTick(tick Tick, feeGrowthGlobal, secondsPerLiquidityGlobal *big.Int) Tick {
+	crossed := Tick{Index: tick.Index, LiquidityGross: tick.LiquidityGross, LiquidityNet: tick.LiquidityNet}
+	if feeGrowthGlobal != nil && tick.FeeGrowthOutside != nil {
+		crossed.FeeGrowthOutside = new(big.Int).Sub(feeGrowthGlobal, tick.FeeGrowthOutside)
+		crossed.FeeGrowthOutside.And(crossed.FeeGrowthOutside, maxUint256)
+	}
+	if secondsPerLiquidityGlobal != nil && tick.SecondsPerLiquidityOutside != nil {
+		crossed.SecondsPerLiquidityOutside = new(big.Int).Sub(secondsPerLiquidityGlobal, tick.SecondsPerLiquidityOutside)
+		crossed.SecondsPerLiquidityOutside.And(crossed.SecondsPerLiquidityOutside, maxUint128)
+	}
+	return crossed
 }
@@ -124,6 +124,26 @@ func TestNewPool(t *testing.T) {
 func TestGetAddress(t *testing.T) {
 	addr, _ := GetAddress(USDC, DAI, constants.Fee001, "")
 	assert.Equal(t, addr, common.HexToAddress("0xE5e30b9aDD54E8E6DDf05b76693ad690fEe56a25"), "matches an example")
+
+	pool, _ := NewPool(
+		USDC, DAI, constants.Fee001, utils.EncodeSqrtRatioX96(constants.One, constants.One), big.NewInt(0),
+		big.NewInt(0), 0, nil,
+	)
+	addr, err := pool.GetAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, addr, common.HexToAddress("0xE5e30b9aDD54E8E6DDf05b76693ad690fEe56a25"), "resolves the default deployment")
+
+	defaultDeployment, err := constants.GetDeployment(1)
+	assert.NoError(t, err)
+	defer constants.Deployments.Register(1, defaultDeployment)
+	constants.Deployments.Register(1, &constants.Deployment{
+		Factory:          common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		PoolInitCodeHash: constants.PoolInitCodeHash,
+	})
+	addr, err = pool.GetAddress()
+	assert.NoError(t, err)
+	expected, _ := utils.ComputePoolAddress(common.HexToAddress("0x1111111111111111111111111111111111111111"), USDC, DAI, constants.Fee001, "")
+	assert.Equal(t, expected, addr, "resolves the overridden deployment")
 }
 
 func TestToken0(t *testing.T) {
@@ -241,9 +261,10 @@ func newTestPool() *Pool {
 		panic(err)
 	}
 
-	pool, err := NewPool(
+	// 50 is not one of the default fee tiers
+	pool, err := NewPoolWithFeeTiers(
 		USDC, DAI, 50, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther, big.NewInt(0),
-		0, p,
+		0, p, NewFeeTiers(map[constants.FeeAmount]int{50: 8}), constants.MaxTickDistance,
 	)
 	if err != nil {
 		panic(err)
@@ -838,3 +859,427 @@ func TestGetInputAmount(t *testing.T) {
 	assert.True(t, inputAmount.Currency.Equal(DAI))
 	assert.Equal(t, inputAmount.Quotient(), big.NewInt(98))
 }
+
+func TestPool_GetOutputAmountWithTrace(t *testing.T) {
+	pool := newTestPoolFee01()
+
+	inputAmount := entities.FromRawAmount(USDC, big.NewInt(1000000))
+	outputAmount, newPool, steps, err := pool.GetOutputAmountWithTrace(inputAmount, nil)
+	assert.NoError(t, err)
+
+	expectedOutputAmount, _, err := pool.GetOutputAmount(inputAmount, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedOutputAmount.Quotient(), outputAmount.Quotient())
+
+	assert.NotEmpty(t, steps)
+	assert.Equal(t, pool.SqrtRatioX96, steps[0].SqrtPriceStartX96)
+	assert.Equal(t, newPool.SqrtRatioX96, steps[len(steps)-1].SqrtPriceEndX96)
+
+	totalIn, totalOut, totalDeltaL := big.NewInt(0), big.NewInt(0), big.NewInt(0)
+	for i, step := range steps {
+		if i > 0 {
+			assert.Equal(t, steps[i-1].SqrtPriceEndX96, step.SqrtPriceStartX96)
+			assert.Equal(t, steps[i-1].LiquidityAfter, step.LiquidityBefore)
+		}
+		totalIn.Add(totalIn, step.AmountIn)
+		totalOut.Add(totalOut, step.AmountOut)
+		totalDeltaL.Add(totalDeltaL, step.DeltaL)
+	}
+	assert.Equal(t, inputAmount.Quotient(), totalIn)
+	assert.Equal(t, outputAmount.Quotient(), totalOut)
+	assert.Equal(t, newPool.ReinvestLiquidity, totalDeltaL)
+}
+
+func TestPool_GetInputAmountWithTrace(t *testing.T) {
+	pool := newTestPoolFee01()
+
+	outputAmount := entities.FromRawAmount(DAI, big.NewInt(1000000))
+	inputAmount, newPool, steps, err := pool.GetInputAmountWithTrace(outputAmount, nil)
+	assert.NoError(t, err)
+
+	totalIn, totalOut := big.NewInt(0), big.NewInt(0)
+	for _, step := range steps {
+		assert.False(t, step.Crossed)
+		totalIn.Add(totalIn, step.AmountIn)
+		totalOut.Add(totalOut, step.AmountOut)
+	}
+	assert.Equal(t, inputAmount.Quotient(), totalIn)
+	assert.Equal(t, outputAmount.Quotient(), totalOut)
+	assert.Equal(t, newPool.SqrtRatioX96, steps[len(steps)-1].SqrtPriceEndX96)
+}
+
+func TestPool_GetOutputAmountWithTrace_CrossTick(t *testing.T) {
+	ticks := []Tick{
+		{Index: -100, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 100, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+		{Index: 200, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 300, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	p, err := NewTickListDataProvider(ticks, constants.TickSpacings[constants.Fee01])
+	assert.NoError(t, err)
+	pool, err := NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther, big.NewInt(0),
+		0, p,
+	)
+	assert.NoError(t, err)
+
+	// DAI is token0, so selling USDC moves the price up through ticks 100 and 200
+	inputAmount := entities.FromRawAmount(USDC, big.NewInt(7e15))
+	_, newPool, steps, err := pool.GetOutputAmountWithTrace(inputAmount, nil)
+	assert.NoError(t, err)
+	assert.True(t, newPool.TickCurrent > 200)
+
+	var crossedTicks []int
+	for _, step := range steps {
+		if step.Crossed && step.Initialized {
+			crossedTicks = append(crossedTicks, step.TickNext)
+		}
+	}
+	assert.Equal(t, []int{100, 200}, crossedTicks)
+	assert.Equal(t, OneEther, steps[0].LiquidityBefore)
+	assert.Equal(t, OneEther, newPool.Liquidity)
+}
+
+func TestPool_InsufficientErrors(t *testing.T) {
+	ticks := []Tick{
+		{Index: -100, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 100, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	p, err := NewTickListDataProvider(ticks, constants.TickSpacings[constants.Fee01])
+	assert.NoError(t, err)
+	pool, err := NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther, big.NewInt(0),
+		0, p,
+	)
+	assert.NoError(t, err)
+
+	// runs past the last initialized tick
+	inputAmount := entities.FromRawAmount(DAI, OneEther)
+	_, _, err = pool.GetOutputAmount(inputAmount, nil)
+	var liquidityErr *InsufficientLiquidityError
+	assert.ErrorAs(t, err, &liquidityErr)
+	assert.ErrorIs(t, err, ErrBelowSmallest)
+	assert.True(t, liquidityErr.AmountIn.Currency.Equal(DAI))
+	assert.True(t, liquidityErr.AmountIn.Quotient().Sign() > 0)
+	assert.True(t, liquidityErr.AmountIn.LessThan(inputAmount.Fraction))
+	assert.True(t, liquidityErr.AmountOut.Currency.Equal(USDC))
+	assert.True(t, liquidityErr.AmountOut.Quotient().Sign() > 0)
+
+	outputAmount := entities.FromRawAmount(DAI, OneEther)
+	_, _, err = pool.GetInputAmount(outputAmount, nil)
+	assert.ErrorAs(t, err, &liquidityErr)
+	assert.ErrorIs(t, err, ErrAtOrAboveLargest)
+	assert.True(t, liquidityErr.AmountOut.Currency.Equal(DAI))
+	assert.True(t, liquidityErr.AmountOut.LessThan(outputAmount.Fraction))
+
+	// reaches the sqrt price limit, which fills the swap up to the limit
+	sqrtPriceLimitX96, err := utils.GetSqrtRatioAtTick(-10)
+	assert.NoError(t, err)
+	partialOutput, filledPool, err := pool.GetOutputAmount(inputAmount, sqrtPriceLimitX96)
+	assert.NoError(t, err)
+	assert.Equal(t, -10, filledPool.TickCurrent)
+	assert.Equal(t, 0, filledPool.SqrtRatioX96.Cmp(sqrtPriceLimitX96))
+	assert.True(t, partialOutput.Currency.Equal(USDC))
+	assert.True(t, partialOutput.Quotient().Sign() > 0)
+
+	partialInput, filledPool, err := pool.GetInputAmount(outputAmount, new(big.Int).Set(mustSqrtRatioAtTick(t, 10)))
+	assert.NoError(t, err)
+	assert.Equal(t, 10, filledPool.TickCurrent)
+	assert.True(t, partialInput.Currency.Equal(USDC))
+
+	// input too low
+	_, _, err = pool.GetOutputAmount(entities.FromRawAmount(DAI, big.NewInt(1)), nil)
+	var inputErr *InsufficientInputAmountError
+	assert.ErrorAs(t, err, &inputErr)
+	assert.Equal(t, big.NewInt(1), inputErr.AmountIn.Quotient())
+}
+
+// updateCountingProvider counts the updates of the ticks of a tick list
+type updateCountingProvider struct {
+	*TickListDataProvider
+	updates int
+}
+
+func (p *updateCountingProvider) UpdateTicks(ticks []Tick) (TickDataProvider, error) {
+	p.updates++
+	return p.TickListDataProvider.UpdateTicks(ticks)
+}
+
+func TestPool_InsufficientErrors_SkipUpdate(t *testing.T) {
+	ticks := []Tick{
+		{Index: -200, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: -100, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 100, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+		{Index: 200, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	list, err := NewTickListDataProvider(ticks, constants.TickSpacings[constants.Fee01])
+	assert.NoError(t, err)
+	p := &updateCountingProvider{TickListDataProvider: list}
+	pool, err := NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One),
+		new(big.Int).Mul(OneEther, big.NewInt(2)), big.NewInt(0), 0, p,
+	)
+	assert.NoError(t, err)
+	// the crossed ticks are only updated when the pool follows an accumulator
+	pool.SecondsPerLiquidityGlobal = big.NewInt(0)
+	pool.SecondsPerLiquidityUpdateTime = 100
+	pool.BlockTimestamp = 110
+
+	// the pool after a swap that is not filled is not built
+	_, _, err = pool.GetOutputAmount(entities.FromRawAmount(DAI, OneEther), nil)
+	assert.ErrorIs(t, err, ErrBelowSmallest)
+	_, _, err = pool.GetInputAmount(entities.FromRawAmount(DAI, OneEther), nil)
+	assert.ErrorIs(t, err, ErrAtOrAboveLargest)
+	_, _, err = pool.GetOutputAmount(entities.FromRawAmount(DAI, big.NewInt(1)), nil)
+	assert.Error(t, err)
+	assert.Equal(t, 0, p.updates)
+
+	// a filled swap crossing a tick updates it
+	_, filledPool, err := pool.GetOutputAmount(entities.FromRawAmount(DAI, big.NewInt(15_000_000_000_000_000)), nil)
+	assert.NoError(t, err)
+	assert.Less(t, filledPool.TickCurrent, -100)
+	assert.Equal(t, 1, p.updates)
+}
+
+func mustSqrtRatioAtTick(t *testing.T, tick int) *big.Int {
+	sqrtRatioX96, err := utils.GetSqrtRatioAtTick(tick)
+	assert.NoError(t, err)
+	return sqrtRatioX96
+}
+
+func TestPool_MintBurn(t *testing.T) {
+	pool := newTestPoolFee01()
+
+	// in range
+	minted, err := pool.Mint(-100, 100, OneEther)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).Mul(OneEther, big.NewInt(2)), minted.Liquidity)
+	assert.Equal(t, OneEther, pool.Liquidity, "does not modify the original pool")
+
+	lower, err := minted.TickDataProvider.GetTick(-100)
+	assert.NoError(t, err)
+	assert.Equal(t, Tick{Index: -100, LiquidityGross: OneEther, LiquidityNet: OneEther}, lower)
+	upper, err := minted.TickDataProvider.GetTick(100)
+	assert.NoError(t, err)
+	assert.Equal(t, Tick{Index: 100, LiquidityGross: OneEther, LiquidityNet: new(big.Int).Neg(OneEther)}, upper)
+	_, isInitialized, err := pool.TickDataProvider.NextInitializedTickWithinFixedDistance(0, false, 480)
+	assert.NoError(t, err)
+	assert.False(t, isInitialized)
+
+	// the added liquidity is used by swaps
+	inputAmount := entities.FromRawAmount(USDC, big.NewInt(1e15))
+	outputBefore, _, err := pool.GetOutputAmount(inputAmount, nil)
+	assert.NoError(t, err)
+	outputAfter, _, err := minted.GetOutputAmount(inputAmount, nil)
+	assert.NoError(t, err)
+	assert.True(t, outputAfter.GreaterThan(outputBefore.Fraction))
+
+	// out of range, on an already initialized tick
+	minted, err = minted.Mint(100, 200, OneEther)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).Mul(OneEther, big.NewInt(2)), minted.Liquidity)
+	upper, err = minted.TickDataProvider.GetTick(100)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).Mul(OneEther, big.NewInt(2)), upper.LiquidityGross)
+	assert.Equal(t, 0, upper.LiquidityNet.Sign())
+
+	// burn everything back
+	burnt, err := minted.Burn(-100, 100, OneEther)
+	assert.NoError(t, err)
+	burnt, err = burnt.Burn(100, 200, OneEther)
+	assert.NoError(t, err)
+	assert.Equal(t, OneEther, burnt.Liquidity)
+	next, isInitialized, err := burnt.TickDataProvider.NextInitializedTickWithinFixedDistance(0, false, 480)
+	assert.NoError(t, err)
+	assert.Equal(t, 480, next)
+	assert.False(t, isInitialized)
+
+	_, err = burnt.Burn(-100, 100, OneEther)
+	assert.ErrorIs(t, err, ErrInsufficientTickLiquidity)
+	_, err = pool.Mint(-105, 100, OneEther)
+	assert.ErrorIs(t, err, ErrTickLower)
+	_, err = pool.Mint(100, -100, OneEther)
+	assert.ErrorIs(t, err, ErrTickOrder)
+	_, err = pool.Mint(-100, 100, big.NewInt(0))
+	assert.ErrorIs(t, err, ErrInvalidLiquidity)
+}
+
+func TestPool_MintWithoutTickData(t *testing.T) {
+	pool, err := NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), big.NewInt(0),
+		big.NewInt(0), 0, nil,
+	)
+	assert.NoError(t, err)
+
+	minted, err := pool.Mint(-100, 100, OneEther)
+	assert.NoError(t, err)
+	assert.Equal(t, OneEther, minted.Liquidity)
+	tick, err := minted.TickDataProvider.GetTick(-100)
+	assert.NoError(t, err)
+	assert.Equal(t, OneEther, tick.LiquidityNet)
+}
+
+func TestPool_MintSyncsRTokens(t *testing.T) {
+	pool := newTestPoolFee01()
+	pool.ReinvestLiquidity = big.NewInt(2e12)
+	pool.ReinvestLiquidityLast = big.NewInt(1e12)
+	pool.RTotalSupply = big.NewInt(1e12)
+
+	minted, err := pool.Mint(-100, 100, OneEther)
+	assert.NoError(t, err)
+	assert.Equal(t, pool.ReinvestLiquidity, minted.ReinvestLiquidityLast)
+	assert.Equal(t, pool.ReinvestLiquidity, minted.ReinvestLiquidity)
+	assert.True(t, minted.RTotalSupply.Cmp(pool.RTotalSupply) > 0)
+
+	// out of range positions do not sync the fees
+	minted, err = pool.Mint(100, 200, OneEther)
+	assert.NoError(t, err)
+	assert.Equal(t, pool.ReinvestLiquidityLast, minted.ReinvestLiquidityLast)
+	assert.Equal(t, pool.RTotalSupply, minted.RTotalSupply)
+}
+
+func TestPool_SwapUpdatesAccumulators(t *testing.T) {
+	pool := newCrossTickTestPool(t)
+	pool.ReinvestLiquidityLast = pool.ReinvestLiquidity
+	pool.RTotalSupply = big.NewInt(1e12)
+	pool.FeeGrowthGlobal = big.NewInt(0)
+	pool.SecondsPerLiquidityGlobal = big.NewInt(0)
+	pool.SecondsPerLiquidityUpdateTime = 100
+	pool.BlockTimestamp = 110
+	pool.GovernmentFeeUnits = 10000
+	pool.TickDataProvider, _ = pool.TickDataProvider.(UpdatableTickDataProvider).UpdateTicks([]Tick{
+		{Index: -100, LiquidityNet: OneEther, LiquidityGross: OneEther, FeeGrowthOutside: big.NewInt(0), SecondsPerLiquidityOutside: big.NewInt(0)},
+		{Index: 100, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther, FeeGrowthOutside: big.NewInt(0), SecondsPerLiquidityOutside: big.NewInt(0)},
+		{Index: 200, LiquidityNet: OneEther, LiquidityGross: OneEther, FeeGrowthOutside: big.NewInt(0), SecondsPerLiquidityOutside: big.NewInt(0)},
+	})
+
+	// selling USDC moves the price up through ticks 100 and 200
+	result, err := pool.SimulateExactIn(entities.FromRawAmount(USDC, big.NewInt(7e15)), nil)
+	assert.NoError(t, err)
+	newPool := result.Pool
+	assert.True(t, result.RTokenMinted.Sign() > 0)
+
+	// the seconds per liquidity is synced once, with the base liquidity before the first crossing
+	secondsPerLiquidity := new(big.Int).Div(new(big.Int).Lsh(big.NewInt(10), 96), OneEther)
+	assert.Equal(t, secondsPerLiquidity, newPool.SecondsPerLiquidityGlobal)
+	assert.Equal(t, uint32(110), newPool.SecondsPerLiquidityUpdateTime)
+
+	// the rTokens are minted when crossing tick 100, there is no base liquidity when crossing tick 200,
+	// 10% of them go to the government
+	lpFee := new(big.Int).Sub(result.RTokenMinted, new(big.Int).Div(result.RTokenMinted, big.NewInt(10)))
+	feeGrowth := new(big.Int).Div(new(big.Int).Mul(lpFee, constants.Q96), OneEther)
+	assert.Equal(t, feeGrowth, newPool.FeeGrowthGlobal)
+
+	for _, index := range []int{100, 200} {
+		tick, err := newPool.TickDataProvider.GetTick(index)
+		assert.NoError(t, err)
+		assert.Equal(t, feeGrowth, tick.FeeGrowthOutside)
+		assert.Equal(t, secondsPerLiquidity, tick.SecondsPerLiquidityOutside)
+	}
+	tick, err := newPool.TickDataProvider.GetTick(-100)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, tick.FeeGrowthOutside.Sign())
+
+	// the original pool is not modified
+	tick, err = pool.TickDataProvider.GetTick(100)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, tick.FeeGrowthOutside.Sign())
+
+	// the fee growth is unknown without the reinvestment state
+	pool.RTotalSupply = nil
+	result, err = pool.SimulateExactIn(entities.FromRawAmount(USDC, big.NewInt(7e15)), nil)
+	assert.NoError(t, err)
+	assert.Nil(t, result.Pool.FeeGrowthGlobal)
+	assert.Equal(t, secondsPerLiquidity, result.Pool.SecondsPerLiquidityGlobal)
+}
+
+func TestPool_MintInitializesTickAccumulators(t *testing.T) {
+	pool := newTestPoolFee01()
+	pool.FeeGrowthGlobal = big.NewInt(5)
+	pool.SecondsPerLiquidityGlobal = big.NewInt(7)
+
+	// without the reinvestment state, the fee growth is lost once the position is in range
+	minted, err := pool.Mint(100, 200, OneEther)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), minted.FeeGrowthGlobal)
+
+	// ticks at or below the current tick start with all the growth so far, ticks above start with none
+	minted, err = pool.Mint(-500, 500, OneEther)
+	assert.NoError(t, err)
+	assert.Nil(t, minted.FeeGrowthGlobal)
+	lower, err := minted.TickDataProvider.GetTick(-500)
+	assert.NoError(t, err)
+	assert.Nil(t, lower.FeeGrowthOutside)
+	assert.Equal(t, big.NewInt(7), lower.SecondsPerLiquidityOutside)
+	upper, err := minted.TickDataProvider.GetTick(500)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, upper.SecondsPerLiquidityOutside.Sign())
+
+	pool.ReinvestLiquidityLast = pool.ReinvestLiquidity
+	pool.RTotalSupply = big.NewInt(1e12)
+	minted, err = pool.Mint(-500, 500, OneEther)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), minted.FeeGrowthGlobal)
+	lower, err = minted.TickDataProvider.GetTick(-500)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), lower.FeeGrowthOutside)
+}
+
+func TestPool_BurnRTokens(t *testing.T) {
+	pool := newTestPoolFee01()
+	_, err := pool.BurnRTokens(big.NewInt(1), false)
+	assert.ErrorIs(t, err, ErrReinvestmentStateUnknown)
+
+	pool.ReinvestLiquidity = big.NewInt(2e12)
+	pool.ReinvestLiquidityLast = big.NewInt(2e12)
+	pool.RTotalSupply = big.NewInt(1e12)
+
+	// half of the supply is worth half of the reinvestment liquidity
+	burnt, err := pool.BurnRTokens(big.NewInt(5e11), false)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1e12), burnt.ReinvestLiquidity)
+	assert.Equal(t, big.NewInt(1e12), burnt.ReinvestLiquidityLast)
+	assert.Equal(t, big.NewInt(5e11), burnt.RTotalSupply)
+	assert.Equal(t, big.NewInt(1e12), pool.RTotalSupply)
+
+	_, err = pool.BurnRTokens(big.NewInt(2e12), true)
+	assert.ErrorIs(t, err, ErrInvalidRTokenQty)
+}
+
+func TestNewPoolWithTickDistance(t *testing.T) {
+	_, err := NewPoolWithTickDistance(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther, big.NewInt(0),
+		0, nil, 0,
+	)
+	assert.ErrorIs(t, err, ErrInvalidTickDistance)
+
+	ticks := []Tick{
+		{Index: -1000, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 1000, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	p, err := NewTickListDataProvider(ticks, constants.TickSpacings[constants.Fee01])
+	assert.NoError(t, err)
+	pool, err := NewPoolWithTickDistance(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther, big.NewInt(0),
+		0, p, 100,
+	)
+	assert.NoError(t, err)
+
+	// every step of the swap travels at most 100 ticks
+	inputAmount := entities.FromRawAmount(USDC, big.NewInt(1e16))
+	_, newPool, steps, err := pool.GetOutputAmountWithTrace(inputAmount, nil)
+	assert.NoError(t, err)
+	assert.True(t, len(steps) > 1)
+	assert.Equal(t, 100, steps[0].TickNext)
+	assert.Equal(t, 100, newPool.TickDistance)
+
+	pool, err = NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther, big.NewInt(0),
+		0, p,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.MaxTickDistance, pool.TickDistance)
+	_, _, steps, err = pool.GetOutputAmountWithTrace(inputAmount, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 480, steps[0].TickNext)
+}
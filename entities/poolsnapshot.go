@@ -0,0 +1,437 @@
+package entities
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+)
+
+// PoolSnapshotVersion is the version of the pool snapshot format, snapshots of other versions are rejected
+const PoolSnapshotVersion = 1
+
+// poolSnapshotMagic prefixes the binary form of a pool snapshot
+var poolSnapshotMagic = []byte("PMSS")
+
+var (
+	ErrUnsupportedSnapshotVersion  = errors.New("unsupported snapshot version")
+	ErrInvalidSnapshot             = errors.New("invalid snapshot")
+	ErrTickDataProviderNotListable = errors.New("tick data provider cannot list its ticks")
+)
+
+// tickLister is implemented by tick data providers that hold all their ticks in memory
+type tickLister interface {
+	Ticks() []Tick
+}
+
+// TokenSnapshot is the serializable form of a token
+type TokenSnapshot struct {
+	ChainID  uint           `json:"chainId"`
+	Address  common.Address `json:"address"`
+	Decimals uint           `json:"decimals"`
+	Symbol   string         `json:"symbol"`
+	Name     string         `json:"name"`
+}
+
+// TickSnapshot is the serializable form of a tick
+type TickSnapshot struct {
+	Index          int      `json:"index"`
+	LiquidityGross *big.Int `json:"liquidityGross"`
+	LiquidityNet   *big.Int `json:"liquidityNet"`
+
+	FeeGrowthOutside           *big.Int `json:"feeGrowthOutside,omitempty"`
+	SecondsPerLiquidityOutside *big.Int `json:"secondsPerLiquidityOutside,omitempty"`
+}
+
+// PoolSnapshot is a serializable and versioned copy of the state of a pool and its ticks.
+// It can be encoded to JSON with encoding/json, or to a compact binary form with MarshalBinary
+type PoolSnapshot struct {
+	Version               int                 `json:"version"`
+	Token0                TokenSnapshot       `json:"token0"`
+	Token1                TokenSnapshot       `json:"token1"`
+	Fee                   constants.FeeAmount `json:"fee"`
+	SqrtRatioX96          *big.Int            `json:"sqrtRatioX96"`
+	Liquidity             *big.Int            `json:"liquidity"`
+	ReinvestLiquidity     *big.Int            `json:"reinvestLiquidity"`
+	ReinvestLiquidityLast *big.Int            `json:"reinvestLiquidityLast,omitempty"`
+	RTotalSupply          *big.Int            `json:"rTotalSupply,omitempty"`
+	TickCurrent           int                 `json:"tickCurrent"`
+	Ticks                 []TickSnapshot      `json:"ticks"`
+
+	FeeGrowthGlobal               *big.Int `json:"feeGrowthGlobal,omitempty"`
+	SecondsPerLiquidityGlobal     *big.Int `json:"secondsPerLiquidityGlobal,omitempty"`
+	SecondsPerLiquidityUpdateTime uint32   `json:"secondsPerLiquidityUpdateTime,omitempty"`
+	GovernmentFeeUnits            uint32   `json:"governmentFeeUnits,omitempty"`
+	BlockTimestamp                uint32   `json:"blockTimestamp,omitempty"`
+
+	// The tick distance of the pool, 0 for the default constants.MaxTickDistance
+	TickDistance int `json:"tickDistance,omitempty"`
+	// The tick spacing of the pool, 0 for the one of its fee tier in DefaultFeeTiers
+	TickSpacing int `json:"tickSpacing,omitempty"`
+}
+
+/**
+ * Takes a snapshot of the pool state
+ * @param pool The pool, its tick data provider must be nil or hold all its ticks in memory, e.g. a TickListDataProvider
+ * @returns The snapshot
+ */
+func NewPoolSnapshot(pool *Pool) (*PoolSnapshot, error) {
+	var ticks []Tick
+	if pool.TickDataProvider != nil {
+		lister, ok := pool.TickDataProvider.(tickLister)
+		if !ok {
+			return nil, ErrTickDataProviderNotListable
+		}
+		ticks = lister.Ticks()
+	}
+
+	snapshot := &PoolSnapshot{
+		Version:               PoolSnapshotVersion,
+		Token0:                newTokenSnapshot(pool.Token0),
+		Token1:                newTokenSnapshot(pool.Token1),
+		Fee:                   pool.Fee,
+		SqrtRatioX96:          pool.SqrtRatioX96,
+		Liquidity:             pool.Liquidity,
+		ReinvestLiquidity:     pool.ReinvestLiquidity,
+		ReinvestLiquidityLast: pool.ReinvestLiquidityLast,
+		RTotalSupply:          pool.RTotalSupply,
+		TickCurrent:           pool.TickCurrent,
+		Ticks:                 make([]TickSnapshot, len(ticks)),
+
+		FeeGrowthGlobal:               pool.FeeGrowthGlobal,
+		SecondsPerLiquidityGlobal:     pool.SecondsPerLiquidityGlobal,
+		SecondsPerLiquidityUpdateTime: pool.SecondsPerLiquidityUpdateTime,
+		GovernmentFeeUnits:            pool.GovernmentFeeUnits,
+		BlockTimestamp:                pool.BlockTimestamp,
+
+		TickDistance: pool.TickDistance,
+		TickSpacing:  pool.TickSpacing,
+	}
+	for i, tick := range ticks {
+		snapshot.Ticks[i] = TickSnapshot{
+			Index:          tick.Index,
+			LiquidityGross: tick.LiquidityGross,
+			LiquidityNet:   tick.LiquidityNet,
+
+			FeeGrowthOutside:           tick.FeeGrowthOutside,
+			SecondsPerLiquidityOutside: tick.SecondsPerLiquidityOutside,
+		}
+	}
+	return snapshot, nil
+}
+
+func newTokenSnapshot(token *entities.Token) TokenSnapshot {
+	return TokenSnapshot{
+		ChainID:  token.ChainId(),
+		Address:  token.Address,
+		Decimals: token.Decimals(),
+		Symbol:   token.Symbol(),
+		Name:     token.Name(),
+	}
+}
+
+func (s TokenSnapshot) token() *entities.Token {
+	return entities.NewToken(s.ChainID, s.Address, s.Decimals, s.Symbol, s.Name)
+}
+
+// ToPool restores the pool from the snapshot, its ticks are loaded into a TickListDataProvider.
+// The fee tier of the pool must be enabled in DefaultFeeTiers for snapshots without a tick spacing
+func (s *PoolSnapshot) ToPool() (*Pool, error) {
+	if s.Version != PoolSnapshotVersion {
+		return nil, ErrUnsupportedSnapshotVersion
+	}
+	if s.SqrtRatioX96 == nil || s.Liquidity == nil || s.ReinvestLiquidity == nil {
+		return nil, ErrInvalidSnapshot
+	}
+
+	tickDistance := s.TickDistance
+	if tickDistance == 0 {
+		tickDistance = constants.MaxTickDistance
+	}
+	if s.TickSpacing < 0 {
+		return nil, ErrInvalidSnapshot
+	}
+	var pool *Pool
+	var err error
+	if s.TickSpacing == 0 {
+		pool, err = NewPoolWithTickDistance(
+			s.Token0.token(), s.Token1.token(), s.Fee, s.SqrtRatioX96, s.Liquidity, s.ReinvestLiquidity, s.TickCurrent,
+			nil, tickDistance,
+		)
+	} else if tickDistance < 0 {
+		err = ErrInvalidTickDistance
+	} else if s.Fee >= constants.FeeMax {
+		err = ErrFeeTooHigh
+	} else {
+		pool, err = newPool(
+			s.Token0.token(), s.Token1.token(), s.Fee, s.SqrtRatioX96, s.Liquidity, s.ReinvestLiquidity, s.TickCurrent,
+			nil, s.TickSpacing, tickDistance,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pool.ReinvestLiquidityLast, pool.RTotalSupply = s.ReinvestLiquidityLast, s.RTotalSupply
+	pool.FeeGrowthGlobal, pool.SecondsPerLiquidityGlobal = s.FeeGrowthGlobal, s.SecondsPerLiquidityGlobal
+	pool.SecondsPerLiquidityUpdateTime = s.SecondsPerLiquidityUpdateTime
+	pool.GovernmentFeeUnits, pool.BlockTimestamp = s.GovernmentFeeUnits, s.BlockTimestamp
+
+	if len(s.Ticks) > 0 {
+		ticks := make([]Tick, len(s.Ticks))
+		for i, tick := range s.Ticks {
+			if tick.LiquidityGross == nil || tick.LiquidityNet == nil {
+				return nil, ErrInvalidSnapshot
+			}
+			ticks[i] = Tick{
+				Index:          tick.Index,
+				LiquidityGross: tick.LiquidityGross,
+				LiquidityNet:   tick.LiquidityNet,
+
+				FeeGrowthOutside:           tick.FeeGrowthOutside,
+				SecondsPerLiquidityOutside: tick.SecondsPerLiquidityOutside,
+			}
+		}
+		pool.TickDataProvider, err = NewTickListDataProvider(ticks, pool.tickSpacing())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pool, nil
+}
+
+// MarshalBinary encodes the snapshot to its compact binary form
+func (s *PoolSnapshot) MarshalBinary() ([]byte, error) {
+	if s.SqrtRatioX96 == nil || s.Liquidity == nil || s.ReinvestLiquidity == nil {
+		return nil, ErrInvalidSnapshot
+	}
+
+	w := &snapshotWriter{}
+	w.Write(poolSnapshotMagic)
+	w.writeUvarint(PoolSnapshotVersion)
+	w.writeToken(s.Token0)
+	w.writeToken(s.Token1)
+	w.writeUvarint(uint64(s.Fee))
+	w.writeBigInt(s.SqrtRatioX96)
+	w.writeBigInt(s.Liquidity)
+	w.writeBigInt(s.ReinvestLiquidity)
+	w.writeOptionalBigInt(s.ReinvestLiquidityLast)
+	w.writeOptionalBigInt(s.RTotalSupply)
+	w.writeVarint(int64(s.TickCurrent))
+	w.writeUvarint(uint64(len(s.Ticks)))
+	for _, tick := range s.Ticks {
+		if tick.LiquidityGross == nil || tick.LiquidityNet == nil {
+			return nil, ErrInvalidSnapshot
+		}
+		w.writeVarint(int64(tick.Index))
+		w.writeBigInt(tick.LiquidityGross)
+		w.writeBigInt(tick.LiquidityNet)
+		w.writeOptionalBigInt(tick.FeeGrowthOutside)
+		w.writeOptionalBigInt(tick.SecondsPerLiquidityOutside)
+	}
+	w.writeOptionalBigInt(s.FeeGrowthGlobal)
+	w.writeOptionalBigInt(s.SecondsPerLiquidityGlobal)
+	w.writeUvarint(uint64(s.SecondsPerLiquidityUpdateTime))
+	w.writeUvarint(uint64(s.GovernmentFeeUnits))
+	w.writeUvarint(uint64(s.BlockTimestamp))
+	w.writeVarint(int64(s.TickDistance))
+	w.writeVarint(int64(s.TickSpacing))
+	return w.Bytes(), nil
+}
+
+// UnmarshalBinary decodes the snapshot from its compact binary form
+func (s *PoolSnapshot) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, poolSnapshotMagic) {
+		return ErrInvalidSnapshot
+	}
+	r := &snapshotReader{Reader: bytes.NewReader(data[len(poolSnapshotMagic):])}
+
+	var decoded PoolSnapshot
+	decoded.Version = int(r.readUvarint())
+	if r.err == nil && decoded.Version != PoolSnapshotVersion {
+		return ErrUnsupportedSnapshotVersion
+	}
+	decoded.Token0 = r.readToken()
+	decoded.Token1 = r.readToken()
+	decoded.Fee = constants.FeeAmount(r.readUvarint())
+	decoded.SqrtRatioX96 = r.readBigInt()
+	decoded.Liquidity = r.readBigInt()
+	decoded.ReinvestLiquidity = r.readBigInt()
+	decoded.ReinvestLiquidityLast = r.readOptionalBigInt()
+	decoded.RTotalSupply = r.readOptionalBigInt()
+	decoded.TickCurrent = int(r.readVarint())
+	numTicks := r.readUvarint()
+	// every tick takes at least 3 bytes, this guards against allocating a huge slice for corrupted data
+	if numTicks > uint64(r.Len())/3 {
+		return ErrInvalidSnapshot
+	}
+	decoded.Ticks = make([]TickSnapshot, numTicks)
+	for i := range decoded.Ticks {
+		decoded.Ticks[i] = TickSnapshot{
+			Index:          int(r.readVarint()),
+			LiquidityGross: r.readBigInt(),
+			LiquidityNet:   r.readBigInt(),
+
+			FeeGrowthOutside:           r.readOptionalBigInt(),
+			SecondsPerLiquidityOutside: r.readOptionalBigInt(),
+		}
+	}
+	decoded.FeeGrowthGlobal = r.readOptionalBigInt()
+	decoded.SecondsPerLiquidityGlobal = r.readOptionalBigInt()
+	decoded.SecondsPerLiquidityUpdateTime = uint32(r.readUvarint())
+	decoded.GovernmentFeeUnits = uint32(r.readUvarint())
+	decoded.BlockTimestamp = uint32(r.readUvarint())
+	decoded.TickDistance = int(r.readVarint())
+	decoded.TickSpacing = int(r.readVarint())
+	if r.err != nil {
+		return r.err
+	}
+	if r.Len() != 0 {
+		return ErrInvalidSnapshot
+	}
+	*s = decoded
+	return nil
+}
+
+type snapshotWriter struct {
+	bytes.Buffer
+}
+
+func (w *snapshotWriter) writeUvarint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func (w *snapshotWriter) writeVarint(v int64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutVarint(buf[:], v)])
+}
+
+func (w *snapshotWriter) writeString(v string) {
+	w.writeUvarint(uint64(len(v)))
+	w.WriteString(v)
+}
+
+// writeBigInt writes the length of the absolute value shifted left by one, with the sign in the lowest bit,
+// followed by the big endian bytes of the absolute value
+func (w *snapshotWriter) writeBigInt(v *big.Int) {
+	abs := v.Bytes()
+	header := uint64(len(abs)) << 1
+	if v.Sign() < 0 {
+		header |= 1
+	}
+	w.writeUvarint(header)
+	w.Write(abs)
+}
+
+func (w *snapshotWriter) writeOptionalBigInt(v *big.Int) {
+	if v == nil {
+		w.WriteByte(0)
+		return
+	}
+	w.WriteByte(1)
+	w.writeBigInt(v)
+}
+
+func (w *snapshotWriter) writeToken(token TokenSnapshot) {
+	w.writeUvarint(uint64(token.ChainID))
+	w.Write(token.Address.Bytes())
+	w.writeUvarint(uint64(token.Decimals))
+	w.writeString(token.Symbol)
+	w.writeString(token.Name)
+}
+
+// snapshotReader reads the binary form of a snapshot, the first error is kept and turns later reads into no-ops
+type snapshotReader struct {
+	*bytes.Reader
+	err error
+}
+
+func (r *snapshotReader) fail(err error) {
+	if r.err == nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		r.err = err
+	}
+}
+
+func (r *snapshotReader) readUvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r)
+	if err != nil {
+		r.fail(err)
+	}
+	return v
+}
+
+func (r *snapshotReader) readVarint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(r)
+	if err != nil {
+		r.fail(err)
+	}
+	return v
+}
+
+func (r *snapshotReader) readBytes(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > uint64(r.Len()) {
+		r.fail(io.ErrUnexpectedEOF)
+		return nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		r.fail(err)
+	}
+	return b
+}
+
+func (r *snapshotReader) readString() string {
+	return string(r.readBytes(r.readUvarint()))
+}
+
+func (r *snapshotReader) readBigInt() *big.Int {
+	header := r.readUvarint()
+	v := new(big.Int).SetBytes(r.readBytes(header >> 1))
+	if header&1 == 1 {
+		v.Neg(v)
+	}
+	return v
+}
+
+func (r *snapshotReader) readOptionalBigInt() *big.Int {
+	if r.err != nil {
+		return nil
+	}
+	present, err := r.ReadByte()
+	if err != nil {
+		r.fail(err)
+		return nil
+	}
+	if present == 0 {
+		return nil
+	}
+	return r.readBigInt()
+}
+
+func (r *snapshotReader) readToken() TokenSnapshot {
+	return TokenSnapshot{
+		ChainID:  uint(r.readUvarint()),
+		Address:  common.BytesToAddress(r.readBytes(common.AddressLength)),
+		Decimals: uint(r.readUvarint()),
+		Symbol:   r.readString(),
+		Name:     r.readString(),
+	}
+}
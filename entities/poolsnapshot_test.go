@@ -0,0 +1,173 @@
+package entities
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+func assertSamePool(t *testing.T, expected, actual *Pool) {
+	assert.True(t, expected.Token0.Equal(actual.Token0))
+	assert.True(t, expected.Token1.Equal(actual.Token1))
+	assert.Equal(t, expected.Token0.Decimals(), actual.Token0.Decimals())
+	assert.Equal(t, expected.Token1.Symbol(), actual.Token1.Symbol())
+	assert.Equal(t, expected.Fee, actual.Fee)
+	assert.Equal(t, expected.SqrtRatioX96, actual.SqrtRatioX96)
+	assert.Equal(t, expected.Liquidity, actual.Liquidity)
+	assert.Equal(t, expected.ReinvestLiquidity.String(), actual.ReinvestLiquidity.String())
+	assert.Equal(t, expected.TickCurrent, actual.TickCurrent)
+	assert.Equal(t, expected.TickDataProvider, actual.TickDataProvider)
+
+	inputAmount := entities.FromRawAmount(expected.Token0, big.NewInt(1e15))
+	expectedOutput, _, err := expected.GetOutputAmount(inputAmount, nil)
+	assert.NoError(t, err)
+	actualOutput, _, err := actual.GetOutputAmount(inputAmount, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedOutput.Quotient(), actualOutput.Quotient())
+}
+
+func TestPoolSnapshot_JSON(t *testing.T) {
+	pool := newTestPoolFee01()
+
+	snapshot, err := NewPoolSnapshot(pool)
+	assert.NoError(t, err)
+	data, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+
+	var decoded PoolSnapshot
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, PoolSnapshotVersion, decoded.Version)
+	restored, err := decoded.ToPool()
+	assert.NoError(t, err)
+	assertSamePool(t, pool, restored)
+	assert.Nil(t, restored.RTotalSupply)
+
+	decoded.Version = PoolSnapshotVersion + 1
+	_, err = decoded.ToPool()
+	assert.ErrorIs(t, err, ErrUnsupportedSnapshotVersion)
+}
+
+func TestPoolSnapshot_Binary(t *testing.T) {
+	pool := newTestPoolFee01()
+	pool.ReinvestLiquidityLast = big.NewInt(1)
+	pool.RTotalSupply = big.NewInt(2)
+
+	snapshot, err := NewPoolSnapshot(pool)
+	assert.NoError(t, err)
+	data, err := snapshot.MarshalBinary()
+	assert.NoError(t, err)
+
+	var decoded PoolSnapshot
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, snapshot.Ticks[1].LiquidityNet, decoded.Ticks[1].LiquidityNet)
+	restored, err := decoded.ToPool()
+	assert.NoError(t, err)
+	assertSamePool(t, pool, restored)
+	assert.Equal(t, big.NewInt(1), restored.ReinvestLiquidityLast)
+	assert.Equal(t, big.NewInt(2), restored.RTotalSupply)
+
+	// smaller than the JSON form
+	jsonData, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+	assert.Less(t, len(data), len(jsonData))
+
+	assert.Error(t, new(PoolSnapshot).UnmarshalBinary(data[:len(data)-1]), "truncated data")
+	assert.ErrorIs(t, new(PoolSnapshot).UnmarshalBinary(append(data, 0)), ErrInvalidSnapshot, "trailing data")
+	assert.ErrorIs(t, new(PoolSnapshot).UnmarshalBinary(data[1:]), ErrInvalidSnapshot, "missing magic")
+	otherVersion := append([]byte{}, data...)
+	otherVersion[len(poolSnapshotMagic)] = PoolSnapshotVersion + 1
+	assert.ErrorIs(t, new(PoolSnapshot).UnmarshalBinary(otherVersion), ErrUnsupportedSnapshotVersion)
+}
+
+func TestPoolSnapshot_WithoutTicks(t *testing.T) {
+	pool := newTestPoolFee01()
+	pool.TickDataProvider = nil
+
+	snapshot, err := NewPoolSnapshot(pool)
+	assert.NoError(t, err)
+	data, err := snapshot.MarshalBinary()
+	assert.NoError(t, err)
+
+	var decoded PoolSnapshot
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	restored, err := decoded.ToPool()
+	assert.NoError(t, err)
+	assert.Nil(t, restored.TickDataProvider)
+}
+
+func TestPoolSnapshot_Accumulators(t *testing.T) {
+	pool := newTestPoolFee01()
+	pool.FeeGrowthGlobal = big.NewInt(3)
+	pool.SecondsPerLiquidityGlobal = big.NewInt(4)
+	pool.SecondsPerLiquidityUpdateTime = 5
+	pool.GovernmentFeeUnits = 6
+	pool.BlockTimestamp = 7
+	pool, err := pool.Mint(-500, 500, OneEther)
+	assert.NoError(t, err)
+
+	snapshot, err := NewPoolSnapshot(pool)
+	assert.NoError(t, err)
+	data, err := snapshot.MarshalBinary()
+	assert.NoError(t, err)
+
+	var decoded PoolSnapshot
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	restored, err := decoded.ToPool()
+	assert.NoError(t, err)
+	// the mint synced the seconds per liquidity at the block timestamp
+	assert.Equal(t, pool.SecondsPerLiquidityGlobal.String(), restored.SecondsPerLiquidityGlobal.String())
+	assert.Equal(t, uint32(7), restored.SecondsPerLiquidityUpdateTime)
+	assert.Equal(t, uint32(6), restored.GovernmentFeeUnits)
+	assert.Equal(t, uint32(7), restored.BlockTimestamp)
+	tick, err := restored.TickDataProvider.GetTick(-500)
+	assert.NoError(t, err)
+	assert.Equal(t, pool.SecondsPerLiquidityGlobal.String(), tick.SecondsPerLiquidityOutside.String())
+	assert.Nil(t, tick.FeeGrowthOutside)
+}
+
+func TestPoolSnapshot_TickDistance(t *testing.T) {
+	pool := newTestPoolFee01()
+	pool.TickDistance = 100
+
+	snapshot, err := NewPoolSnapshot(pool)
+	assert.NoError(t, err)
+	data, err := snapshot.MarshalBinary()
+	assert.NoError(t, err)
+
+	var decoded PoolSnapshot
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	restored, err := decoded.ToPool()
+	assert.NoError(t, err)
+	assert.Equal(t, 100, restored.TickDistance)
+}
+
+func TestPoolSnapshot_TickSpacing(t *testing.T) {
+	pool, err := NewPoolWithFeeTiers(
+		USDC, DAI, 77, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther, big.NewInt(0), 0, nil,
+		fixedFeeTiers(7), constants.MaxTickDistance,
+	)
+	assert.NoError(t, err)
+
+	snapshot, err := NewPoolSnapshot(pool)
+	assert.NoError(t, err)
+	data, err := snapshot.MarshalBinary()
+	assert.NoError(t, err)
+
+	// the fee tier of the pool is not in the default registry, the tick spacing of the snapshot is used instead
+	var decoded PoolSnapshot
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, 7, decoded.TickSpacing)
+	restored, err := decoded.ToPool()
+	assert.NoError(t, err)
+	assert.Equal(t, 7, restored.TickSpacing)
+
+	decoded.TickSpacing = 0
+	_, err = decoded.ToPool()
+	assert.ErrorIs(t, err, ErrUnknownFeeTier)
+}
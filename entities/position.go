@@ -40,6 +40,9 @@ func NewPosition(pool *Pool, liquidity *big.Int, tickLower int, tickUpper int) (
 	if tickLower >= tickUpper {
 		return nil, ErrTickOrder
 	}
+	if pool.tickSpacing() <= 0 {
+		return nil, ErrUnknownFeeTier
+	}
 	if tickLower < utils.MinTick || tickLower%pool.tickSpacing() != 0 {
 		return nil, ErrTickLower
 	}
@@ -151,7 +154,7 @@ func (p *Position) MintAmountsWithSlippage(slippageTolerance *entities.Percent)
 	if err != nil {
 		return nil, nil, err
 	}
-	poolLower, err := NewPool(p.Pool.Token0, p.Pool.Token1, p.Pool.Fee, sqrtRatioX96Lower, big.NewInt(0) /* liquidity doesn't matter */, big.NewInt(0), tickLower, nil)
+	poolLower, err := p.Pool.WithState(sqrtRatioX96Lower, big.NewInt(0) /* liquidity doesn't matter */, big.NewInt(0), tickLower, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -159,7 +162,7 @@ func (p *Position) MintAmountsWithSlippage(slippageTolerance *entities.Percent)
 	if err != nil {
 		return nil, nil, err
 	}
-	poolUpper, err := NewPool(p.Pool.Token0, p.Pool.Token1, p.Pool.Fee, sqrtRatioX96Upper, big.NewInt(0) /* liquidity doesn't matter */, big.NewInt(0), tickUpper, nil)
+	poolUpper, err := p.Pool.WithState(sqrtRatioX96Upper, big.NewInt(0) /* liquidity doesn't matter */, big.NewInt(0), tickUpper, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -212,7 +215,7 @@ func (p *Position) BurnAmountsWithSlippage(slippageTolerance *entities.Percent)
 	if err != nil {
 		return nil, nil, err
 	}
-	poolLower, err := NewPool(p.Pool.Token0, p.Pool.Token1, p.Pool.Fee, sqrtRatioX96Lower, big.NewInt(0) /* liquidity doesn't matter */, big.NewInt(0), tickLower, nil)
+	poolLower, err := p.Pool.WithState(sqrtRatioX96Lower, big.NewInt(0) /* liquidity doesn't matter */, big.NewInt(0), tickLower, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -220,7 +223,7 @@ func (p *Position) BurnAmountsWithSlippage(slippageTolerance *entities.Percent)
 	if err != nil {
 		return nil, nil, err
 	}
-	poolUpper, err := NewPool(p.Pool.Token0, p.Pool.Token1, p.Pool.Fee, sqrtRatioX96Upper, big.NewInt(0) /* liquidity doesn't matter */, big.NewInt(0), tickUpper, nil)
+	poolUpper, err := p.Pool.WithState(sqrtRatioX96Upper, big.NewInt(0) /* liquidity doesn't matter */, big.NewInt(0), tickUpper, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,106 @@
+package entities
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+var (
+	ErrFeeGrowthUnknown = errors.New("fee growth unknown")
+)
+
+// PositionFees is the fees earned by a position since its fee growth inside was last recorded
+type PositionFees struct {
+	FeeGrowthInside *big.Int                 // The current fee growth inside the position range, to record once the fees are collected
+	RTokens         *big.Int                 // The reinvestment tokens owed to the position
+	Amount0         *entities.CurrencyAmount // The amount of token0 the reinvestment tokens can be burnt for at the current price
+	Amount1         *entities.CurrencyAmount // The amount of token1 the reinvestment tokens can be burnt for at the current price
+}
+
+/**
+ * Returns the fee growth inside a tick range, including the fees accumulated by the pool since it last minted
+ * reinvestment tokens. The pool must have its reinvestment state and fee growth set, and both ticks must be
+ * initialized with their FeeGrowthOutside set
+ * @param tickLower The lower tick of the range
+ * @param tickUpper The upper tick of the range
+ * @returns The fee growth inside the range, as a Q96 value
+ */
+func (p *Pool) FeeGrowthInside(tickLower, tickUpper int) (*big.Int, error) {
+	if tickLower >= tickUpper {
+		return nil, ErrTickOrder
+	}
+	if p.FeeGrowthGlobal == nil || p.ReinvestLiquidityLast == nil || p.RTotalSupply == nil || p.TickDataProvider == nil {
+		return nil, ErrFeeGrowthUnknown
+	}
+	lower, err := p.feeGrowthOutside(tickLower)
+	if err != nil {
+		return nil, err
+	}
+	upper, err := p.feeGrowthOutside(tickUpper)
+	if err != nil {
+		return nil, err
+	}
+
+	var feeGrowthInside *big.Int
+	switch {
+	case p.TickCurrent < tickLower:
+		feeGrowthInside = new(big.Int).Sub(lower, upper)
+	case p.TickCurrent >= tickUpper:
+		feeGrowthInside = new(big.Int).Sub(upper, lower)
+	default:
+		// the pool would mint the reinvestment tokens for the pending fees before reading the fee growth
+		rMintQty := utils.CalcRMintQty(p.ReinvestLiquidity, p.ReinvestLiquidityLast, p.Liquidity, p.RTotalSupply)
+		feeGrowthGlobal := p.growFeeGrowthGlobal(p.FeeGrowthGlobal, rMintQty, p.Liquidity)
+		feeGrowthInside = new(big.Int).Sub(feeGrowthGlobal, lower)
+		feeGrowthInside.Sub(feeGrowthInside, upper)
+	}
+	return feeGrowthInside.And(feeGrowthInside, maxUint256), nil
+}
+
+func (p *Pool) feeGrowthOutside(index int) (*big.Int, error) {
+	tick, err := p.TickDataProvider.GetTick(index)
+	if err != nil {
+		return nil, err
+	}
+	if tick.Index != index || tick.FeeGrowthOutside == nil {
+		return nil, ErrFeeGrowthUnknown
+	}
+	return tick.FeeGrowthOutside, nil
+}
+
+/**
+ * Returns the fees earned by the position since its fee growth inside was last recorded, as reinvestment tokens
+ * and as the token amounts they can be burnt for at the current pool price
+ * @param feeGrowthInsideLast The fee growth inside the position range when the fees were last collected
+ * @returns The fees owed to the position
+ */
+func (p *Position) FeesOwed(feeGrowthInsideLast *big.Int) (*PositionFees, error) {
+	feeGrowthInside, err := p.Pool.FeeGrowthInside(p.TickLower, p.TickUpper)
+	if err != nil {
+		return nil, err
+	}
+	growth := new(big.Int).Sub(feeGrowthInside, feeGrowthInsideLast)
+	growth.And(growth, maxUint256)
+	rTokens := utils.MulDivRoundingDown(growth, p.Liquidity, constants.Q96)
+
+	// burning the reinvestment tokens first mints the ones for the pending fees, then returns their share of the
+	// reinvestment liquidity
+	rMintQty := utils.CalcRMintQty(p.Pool.ReinvestLiquidity, p.Pool.ReinvestLiquidityLast, p.Pool.Liquidity, p.Pool.RTotalSupply)
+	rTotalSupply := new(big.Int).Add(p.Pool.RTotalSupply, rMintQty)
+	reinvestLiquidityDelta := big.NewInt(0)
+	if rTotalSupply.Sign() > 0 {
+		reinvestLiquidityDelta = utils.MulDivRoundingDown(rTokens, p.Pool.ReinvestLiquidity, rTotalSupply)
+	}
+
+	return &PositionFees{
+		FeeGrowthInside: feeGrowthInside,
+		RTokens:         rTokens,
+		Amount0:         entities.FromRawAmount(p.Pool.Token0, utils.GetQty0FromBurnRTokens(p.Pool.SqrtRatioX96, reinvestLiquidityDelta)),
+		Amount1:         entities.FromRawAmount(p.Pool.Token1, utils.GetQty1FromBurnRTokens(p.Pool.SqrtRatioX96, reinvestLiquidityDelta)),
+	}, nil
+}
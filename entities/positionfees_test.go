@@ -0,0 +1,93 @@
+package entities
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+func TestPosition_FeesOwed(t *testing.T) {
+	pool, err := NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), big.NewInt(0),
+		big.NewInt(1e12), 0, nil,
+	)
+	assert.NoError(t, err)
+	pool.ReinvestLiquidityLast = big.NewInt(1e12)
+	pool.RTotalSupply = big.NewInt(1e12)
+	pool.FeeGrowthGlobal = big.NewInt(0)
+	pool, err = pool.Mint(-500, 500, OneEther)
+	assert.NoError(t, err)
+
+	feeGrowthInsideLast, err := pool.FeeGrowthInside(-500, 500)
+	assert.NoError(t, err)
+	position, err := NewPosition(pool, OneEther, -500, 500)
+	assert.NoError(t, err)
+	fees, err := position.FeesOwed(feeGrowthInsideLast)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, fees.RTokens.Sign())
+
+	result, err := pool.SimulateExactIn(entities.FromRawAmount(USDC, big.NewInt(1e15)), nil)
+	assert.NoError(t, err)
+	position, err = NewPosition(result.Pool, OneEther, -500, 500)
+	assert.NoError(t, err)
+	fees, err = position.FeesOwed(feeGrowthInsideLast)
+	assert.NoError(t, err)
+
+	// the position is the only base liquidity, it owns all the reinvestment tokens minted for the swap fees
+	rMintQty := utils.CalcRMintQty(
+		result.Pool.ReinvestLiquidity, result.Pool.ReinvestLiquidityLast, result.Pool.Liquidity, result.Pool.RTotalSupply,
+	)
+	assert.True(t, rMintQty.Sign() > 0)
+	diff := new(big.Int).Sub(rMintQty, fees.RTokens)
+	assert.True(t, diff.Sign() >= 0 && diff.Cmp(big.NewInt(1)) <= 0)
+	assert.True(t, fees.Amount0.Currency.Equal(result.Pool.Token0))
+	assert.True(t, fees.Amount0.Quotient().Sign() > 0)
+	assert.True(t, fees.Amount1.Quotient().Sign() > 0)
+
+	// recording the fee growth inside resets the fees owed
+	fees, err = position.FeesOwed(fees.FeeGrowthInside)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, fees.RTokens.Sign())
+
+	// the fee growth of the ticks is unknown
+	pool.FeeGrowthGlobal = nil
+	position, err = NewPosition(pool, OneEther, -500, 500)
+	assert.NoError(t, err)
+	_, err = position.FeesOwed(feeGrowthInsideLast)
+	assert.ErrorIs(t, err, ErrFeeGrowthUnknown)
+}
+
+func TestPool_FeeGrowthInside(t *testing.T) {
+	ticks := []Tick{
+		{Index: -100, LiquidityNet: OneEther, LiquidityGross: OneEther, FeeGrowthOutside: big.NewInt(10)},
+		{Index: 100, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther, FeeGrowthOutside: big.NewInt(3)},
+	}
+	p, err := NewTickListDataProvider(ticks, constants.TickSpacings[constants.Fee01])
+	assert.NoError(t, err)
+	pool, err := NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther,
+		big.NewInt(1e12), 0, p,
+	)
+	assert.NoError(t, err)
+	pool.ReinvestLiquidityLast = pool.ReinvestLiquidity
+	pool.RTotalSupply = big.NewInt(1e12)
+	pool.FeeGrowthGlobal = big.NewInt(20)
+
+	feeGrowthInside, err := pool.FeeGrowthInside(-100, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(7), feeGrowthInside)
+
+	// the growth wraps around like an uint256
+	pool.FeeGrowthGlobal = big.NewInt(5)
+	feeGrowthInside, err = pool.FeeGrowthInside(-100, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).Sub(maxUint256, big.NewInt(7)), feeGrowthInside)
+
+	_, err = pool.FeeGrowthInside(-100, 200)
+	assert.ErrorIs(t, err, ErrFeeGrowthUnknown)
+}
@@ -0,0 +1,90 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+)
+
+// SwapResult represents the outcome of a simulated swap, including the fees earned by the pool.
+// In ProMM swap fees are not paid out, they are reinvested into the pool as reinvestment liquidity
+// and LPs are credited with reinvestment tokens (rTokens) when an initialized tick is crossed.
+type SwapResult struct {
+	InputAmount             *entities.CurrencyAmount // The amount of input token, fees included
+	OutputAmount            *entities.CurrencyAmount // The amount of output token
+	Pool                    *Pool                    // The pool with state updated after the swap
+	Steps                   []SwapStep               // The steps the swap went through, in order
+	FeeAmount               *entities.CurrencyAmount // The total fee paid, in terms of the input token
+	ReinvestLiquidityMinted *big.Int                 // The reinvestment liquidity minted from the fees, i.e. the sum of deltaL
+	RTokenMinted            *big.Int                 // The change of the rToken supply, nil if the reinvestment state of the pool is unknown
+}
+
+/**
+ * Simulates an exact input swap and returns its outcome, including the fee breakdown
+ * @param inputAmount The input amount for which to quote the output amount
+ * @param sqrtPriceLimitX96 The Q64.96 sqrt price limit
+ * @returns The swap result, whose input amount is the part of the input amount filled before reaching the limit
+ */
+func (p *Pool) SimulateExactIn(inputAmount *entities.CurrencyAmount, sqrtPriceLimitX96 *big.Int) (*SwapResult, error) {
+	outputAmount, pool, result, err := p.getOutputAmount(inputAmount, sqrtPriceLimitX96)
+	if err != nil {
+		return nil, err
+	}
+	filled := entities.FromRawAmount(
+		inputAmount.Currency, new(big.Int).Sub(inputAmount.Quotient(), result.amountSpecifiedRemaining),
+	)
+	return newSwapResult(inputAmount.Currency.Wrapped(), filled, outputAmount, pool, result), nil
+}
+
+/**
+ * Simulates an exact output swap and returns its outcome, including the fee breakdown
+ * @param outputAmount The output amount for which to quote the input amount
+ * @param sqrtPriceLimitX96 The Q64.96 sqrt price limit
+ * @returns The swap result, whose output amount is the part of the output amount filled before reaching the limit
+ */
+func (p *Pool) SimulateExactOut(outputAmount *entities.CurrencyAmount, sqrtPriceLimitX96 *big.Int) (*SwapResult, error) {
+	inputAmount, pool, result, err := p.getInputAmount(outputAmount, sqrtPriceLimitX96)
+	if err != nil {
+		return nil, err
+	}
+	filled := entities.FromRawAmount(
+		outputAmount.Currency, new(big.Int).Add(outputAmount.Quotient(), result.amountSpecifiedRemaining),
+	)
+	return newSwapResult(inputAmount.Currency.Wrapped(), inputAmount, filled, pool, result), nil
+}
+
+func newSwapResult(
+	inputToken *entities.Token, inputAmount, outputAmount *entities.CurrencyAmount, pool *Pool, result *swapResult,
+) *SwapResult {
+	zeroForOne := inputToken.Equal(pool.Token0)
+	fee := big.NewInt(0)
+	reinvestLiquidityMinted := big.NewInt(0)
+	for _, step := range result.steps {
+		fee.Add(fee, stepFee(step, zeroForOne))
+		reinvestLiquidityMinted.Add(reinvestLiquidityMinted, step.DeltaL)
+	}
+	return &SwapResult{
+		InputAmount:             inputAmount,
+		OutputAmount:            outputAmount,
+		Pool:                    pool,
+		Steps:                   result.steps,
+		FeeAmount:               entities.FromRawAmount(inputToken, fee),
+		ReinvestLiquidityMinted: reinvestLiquidityMinted,
+		RTokenMinted:            result.rTokenMinted,
+	}
+}
+
+// stepFee returns the value of the reinvestment liquidity minted by a step, in terms of the input token.
+// Reinvestment liquidity is full range, so deltaL is worth deltaL / sqrtP of token0 plus deltaL * sqrtP of token1
+func stepFee(step SwapStep, zeroForOne bool) *big.Int {
+	if step.DeltaL.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	fee := new(big.Int).Lsh(step.DeltaL, 1)
+	if zeroForOne {
+		return fee.Mul(fee, constants.Q96).Div(fee, step.SqrtPriceEndX96)
+	}
+	return fee.Mul(fee, step.SqrtPriceEndX96).Div(fee, constants.Q96)
+}
@@ -0,0 +1,103 @@
+package entities
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+func newCrossTickTestPool(t *testing.T) *Pool {
+	ticks := []Tick{
+		{Index: -100, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 100, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+		{Index: 200, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 300, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	p, err := NewTickListDataProvider(ticks, constants.TickSpacings[constants.Fee01])
+	assert.NoError(t, err)
+	pool, err := NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther,
+		big.NewInt(1e12), 0, p,
+	)
+	assert.NoError(t, err)
+	return pool
+}
+
+func TestPool_SimulateExactIn(t *testing.T) {
+	pool := newTestPoolFee01()
+
+	inputAmount := entities.FromRawAmount(USDC, big.NewInt(1000000))
+	result, err := pool.SimulateExactIn(inputAmount, nil)
+	assert.NoError(t, err)
+
+	outputAmount, newPool, err := pool.GetOutputAmount(inputAmount, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, outputAmount.Quotient(), result.OutputAmount.Quotient())
+	assert.Equal(t, newPool.SqrtRatioX96, result.Pool.SqrtRatioX96)
+	assert.Equal(t, inputAmount.Quotient(), result.InputAmount.Quotient())
+
+	// 0.1% of 1 USDC
+	assert.True(t, result.FeeAmount.Currency.Equal(USDC))
+	assert.Equal(t, big.NewInt(1000), result.FeeAmount.Quotient())
+	assert.Equal(t, result.Pool.ReinvestLiquidity, result.ReinvestLiquidityMinted)
+
+	// the reinvestment state of the pool is unknown
+	assert.Nil(t, result.RTokenMinted)
+}
+
+func TestPool_SimulateExactOut(t *testing.T) {
+	pool := newTestPoolFee01()
+
+	outputAmount := entities.FromRawAmount(USDC, big.NewInt(1000000))
+	result, err := pool.SimulateExactOut(outputAmount, nil)
+	assert.NoError(t, err)
+
+	inputAmount, _, err := pool.GetInputAmount(outputAmount, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, inputAmount.Quotient(), result.InputAmount.Quotient())
+	assert.True(t, result.FeeAmount.Currency.Equal(DAI))
+	assert.True(t, result.FeeAmount.Quotient().Sign() > 0)
+	assert.True(t, result.FeeAmount.Quotient().Cmp(result.InputAmount.Quotient()) < 0)
+}
+
+func TestPool_SimulateExactIn_SqrtPriceLimit(t *testing.T) {
+	pool := newCrossTickTestPool(t)
+	sqrtPriceLimitX96, err := utils.GetSqrtRatioAtTick(50)
+	assert.NoError(t, err)
+
+	inputAmount := entities.FromRawAmount(USDC, OneEther)
+	result, err := pool.SimulateExactIn(inputAmount, sqrtPriceLimitX96)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Pool.SqrtRatioX96.Cmp(sqrtPriceLimitX96))
+	assert.True(t, result.InputAmount.LessThan(inputAmount.Fraction), "reports the filled input amount")
+
+	filled, err := pool.SimulateExactIn(result.InputAmount, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, result.OutputAmount.Quotient(), filled.OutputAmount.Quotient())
+}
+
+func TestPool_SimulateExactIn_RTokenMinted(t *testing.T) {
+	pool := newCrossTickTestPool(t)
+	pool.ReinvestLiquidityLast = big.NewInt(1e12)
+	pool.RTotalSupply = big.NewInt(1e12)
+
+	// does not cross any initialized tick, fees are only accrued
+	result, err := pool.SimulateExactIn(entities.FromRawAmount(USDC, big.NewInt(1e12)), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), result.RTokenMinted)
+	assert.Equal(t, pool.RTotalSupply, result.Pool.RTotalSupply)
+	assert.Equal(t, pool.ReinvestLiquidityLast, result.Pool.ReinvestLiquidityLast)
+
+	// crosses ticks 100 and 200
+	result, err = pool.SimulateExactIn(entities.FromRawAmount(USDC, big.NewInt(7e15)), nil)
+	assert.NoError(t, err)
+	assert.True(t, result.RTokenMinted.Sign() > 0)
+	assert.Equal(t, new(big.Int).Add(pool.RTotalSupply, result.RTokenMinted), result.Pool.RTotalSupply)
+	assert.True(t, result.Pool.ReinvestLiquidityLast.Cmp(pool.ReinvestLiquidityLast) > 0)
+	assert.True(t, result.Pool.ReinvestLiquidityLast.Cmp(result.Pool.ReinvestLiquidity) < 0)
+}
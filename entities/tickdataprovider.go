@@ -6,6 +6,11 @@ type Tick struct {
 	Index          int
 	LiquidityGross *big.Int
 	LiquidityNet   *big.Int
+
+	// The optional fee growth and seconds per liquidity on the other side of the tick from the current tick,
+	// as Q96 values, nil if unknown
+	FeeGrowthOutside           *big.Int
+	SecondsPerLiquidityOutside *big.Int
 }
 
 // Provides information about ticks
@@ -32,3 +37,14 @@ type TickDataProvider interface {
 	 */
 	NextInitializedTickWithinFixedDistance(tick int, lte bool, distance int) (int, bool, error)
 }
+
+// A TickDataProvider that can produce an updated copy of itself, used to simulate changes of the pool liquidity
+type UpdatableTickDataProvider interface {
+	TickDataProvider
+
+	/**
+	 * Return a copy of the provider with the given ticks inserted or replaced, the provider itself is not modified
+	 * @param ticks The new state of the ticks, ticks with zero LiquidityGross are removed
+	 */
+	UpdateTicks(ticks []Tick) (TickDataProvider, error)
+}
@@ -277,7 +277,7 @@ func binarySearch(ticks []Tick, tick int) (int, error) {
 
 	// if we get here, we didn't find a tick that is less than or equal to tick
 	// so we return the index of the tick that is closest to tick
-	if ticks[start].Index < tick {
+	if start < len(ticks) && ticks[start].Index < tick {
 		return start, nil
 	} else {
 		return start - 1, nil
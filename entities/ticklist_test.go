@@ -52,6 +52,21 @@ func TestIsAtOrAboveSmallest(t *testing.T) {
 	assert.True(t, isAtOrAboveLargest2)
 }
 
+func TestGetTick(t *testing.T) {
+	ticks := []Tick{lowTick, midTick, highTick}
+
+	tick, err := GetTick(ticks, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, midTick, tick, "returns the tick immediately below")
+
+	tick, err = GetTick(ticks, utils.MaxTick)
+	assert.NoError(t, err)
+	assert.Equal(t, highTick, tick, "returns the largest tick for ticks above it")
+
+	_, err = GetTick(ticks, utils.MinTick)
+	assert.ErrorIs(t, err, ErrBelowSmallest)
+}
+
 func TestNextInitializedTick(t *testing.T) {
 	ticks := []Tick{lowTick, midTick, highTick}
 
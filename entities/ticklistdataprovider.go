@@ -1,5 +1,11 @@
 package entities
 
+import (
+	"sort"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+)
+
 // A data provider for ticks that is backed by an in-memory array of ticks.
 type TickListDataProvider struct {
 	ticks []Tick
@@ -23,3 +29,33 @@ func (p *TickListDataProvider) NextInitializedTickWithinOneWord(tick int, lte bo
 func (p *TickListDataProvider) NextInitializedTickWithinFixedDistance(tick int, lte bool, distance int) (int, bool, error) {
 	return NextInitializedTickWithinFixedDistance(p.ticks, tick, lte, distance)
 }
+
+func (p *TickListDataProvider) UpdateTicks(ticks []Tick) (TickDataProvider, error) {
+	updated := make([]Tick, len(p.ticks))
+	copy(updated, p.ticks)
+
+	for _, tick := range ticks {
+		i := sort.Search(len(updated), func(i int) bool {
+			return updated[i].Index >= tick.Index
+		})
+		found := i < len(updated) && updated[i].Index == tick.Index
+		remove := tick.LiquidityGross == nil || tick.LiquidityGross.Cmp(constants.Zero) == 0
+
+		switch {
+		case found && remove:
+			updated = append(updated[:i], updated[i+1:]...)
+		case found:
+			updated[i] = tick
+		case !remove:
+			updated = append(updated, Tick{})
+			copy(updated[i+1:], updated[i:])
+			updated[i] = tick
+		}
+	}
+	return &TickListDataProvider{ticks: updated}, nil
+}
+
+// Ticks returns the ticks of the provider, sorted by index
+func (p *TickListDataProvider) Ticks() []Tick {
+	return p.ticks
+}
@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"errors"
+
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+/**
+ * Returns the initialized tick immediately below a tick. The pool contract needs it as a hint to insert the tick into
+ * its initialized tick list when liquidity is first added at the tick. As in the list of the pool contract, utils.MinTick
+ * is returned when no tick is initialized below the tick
+ * @param tick The tick liquidity is added at
+ */
+func (p *Pool) PreviousInitializedTick(tick int) (int, error) {
+	if p.TickDataProvider == nil || tick <= utils.MinTick {
+		return utils.MinTick, nil
+	}
+	// the ticks below are looked up word by word, as GetTick only has to return initialized ticks
+	for next := tick - 1; next > utils.MinTick; next-- {
+		previous, initialized, err := p.TickDataProvider.NextInitializedTickWithinOneWord(next, true, p.tickSpacing())
+		if errors.Is(err, ErrBelowSmallest) || errors.Is(err, ErrEmptyTickList) {
+			return utils.MinTick, nil
+		}
+		if err != nil {
+			return ZeroValueTickIndex, err
+		}
+		if initialized {
+			return previous, nil
+		}
+		next = previous
+	}
+	return utils.MinTick, nil
+}
+
+/**
+ * Returns the ticksPrevious hints of a mint, i.e. the initialized ticks immediately below the ticks of the position
+ * @param tickLower The lower tick of the position
+ * @param tickUpper The upper tick of the position
+ */
+func (p *Pool) TicksPrevious(tickLower, tickUpper int) ([2]int, error) {
+	if tickLower >= tickUpper {
+		return [2]int{}, ErrTickOrder
+	}
+	if tickLower < utils.MinTick {
+		return [2]int{}, ErrTickLower
+	}
+	if tickUpper > utils.MaxTick {
+		return [2]int{}, ErrTickUpper
+	}
+
+	lowerPrevious, err := p.PreviousInitializedTick(tickLower)
+	if err != nil {
+		return [2]int{}, err
+	}
+	upperPrevious, err := p.PreviousInitializedTick(tickUpper)
+	if err != nil {
+		return [2]int{}, err
+	}
+	return [2]int{lowerPrevious, upperPrevious}, nil
+}
+
+// TicksPrevious returns the ticksPrevious hints to mint the position
+func (p *Position) TicksPrevious() ([2]int, error) {
+	return p.Pool.TicksPrevious(p.TickLower, p.TickUpper)
+}
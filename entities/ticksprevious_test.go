@@ -0,0 +1,108 @@
+package entities
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+func TestPool_TicksPrevious(t *testing.T) {
+	ticks := []Tick{
+		{Index: -100, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 100, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	list, err := NewTickListDataProvider(ticks, 10)
+	assert.NoError(t, err)
+	linked, err := NewLinkedListTickDataProvider(ticks, 10)
+	assert.NoError(t, err)
+
+	for _, provider := range []TickDataProvider{list, linked} {
+		pool, err := NewPool(
+			USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther,
+			big.NewInt(0), 0, provider,
+		)
+		assert.NoError(t, err)
+
+		ticksPrevious, err := pool.TicksPrevious(-200, -100)
+		assert.NoError(t, err)
+		assert.Equal(t, [2]int{utils.MinTick, utils.MinTick}, ticksPrevious)
+
+		// the hint of an initialized tick is the tick below it, the one of a new tick the tick it is inserted after
+		ticksPrevious, err = pool.TicksPrevious(-100, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, [2]int{utils.MinTick, -100}, ticksPrevious)
+
+		ticksPrevious, err = pool.TicksPrevious(0, 200)
+		assert.NoError(t, err)
+		assert.Equal(t, [2]int{-100, 100}, ticksPrevious)
+
+		_, err = pool.TicksPrevious(100, 100)
+		assert.ErrorIs(t, err, ErrTickOrder)
+
+		position, err := NewPosition(pool, OneEther, -50, 150)
+		assert.NoError(t, err)
+		ticksPrevious, err = position.TicksPrevious()
+		assert.NoError(t, err)
+		assert.Equal(t, [2]int{-100, 100}, ticksPrevious)
+	}
+
+	// pools without ticks only have the head of the list
+	pool, err := NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), big.NewInt(0),
+		big.NewInt(0), 0, nil,
+	)
+	assert.NoError(t, err)
+	ticksPrevious, err := pool.TicksPrevious(-100, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, [2]int{utils.MinTick, utils.MinTick}, ticksPrevious)
+}
+
+// exactTickProvider only returns initialized ticks from GetTick, and wraps the errors of the tick list
+type exactTickProvider struct {
+	*TickListDataProvider
+}
+
+func (p exactTickProvider) GetTick(tick int) (Tick, error) {
+	found, err := p.TickListDataProvider.GetTick(tick)
+	if err != nil || found.Index != tick {
+		return EmptyTick, fmt.Errorf("tick %d: %w", tick, ErrInvalidTickIndex)
+	}
+	return found, nil
+}
+
+func (p exactTickProvider) NextInitializedTickWithinOneWord(tick int, lte bool, tickSpacing int) (int, bool, error) {
+	next, initialized, err := p.TickListDataProvider.NextInitializedTickWithinOneWord(tick, lte, tickSpacing)
+	if err != nil {
+		return next, initialized, fmt.Errorf("tick %d: %w", tick, err)
+	}
+	return next, initialized, nil
+}
+
+func TestPool_TicksPrevious_ExactTicks(t *testing.T) {
+	// the ticks are words apart
+	ticks := []Tick{
+		{Index: -100 * 256 * 10, LiquidityNet: OneEther, LiquidityGross: OneEther},
+		{Index: 100 * 256 * 10, LiquidityNet: new(big.Int).Neg(OneEther), LiquidityGross: OneEther},
+	}
+	list, err := NewTickListDataProvider(ticks, 10)
+	assert.NoError(t, err)
+	pool, err := NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), OneEther,
+		big.NewInt(0), 0, exactTickProvider{list},
+	)
+	assert.NoError(t, err)
+
+	ticksPrevious, err := pool.TicksPrevious(0, 100*256*10+10)
+	assert.NoError(t, err)
+	assert.Equal(t, [2]int{-100 * 256 * 10, 100 * 256 * 10}, ticksPrevious)
+
+	// the wrapped ErrBelowSmallest of the provider is the head of the list
+	ticksPrevious, err = pool.TicksPrevious(-100*256*10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, [2]int{utils.MinTick, -100 * 256 * 10}, ticksPrevious)
+}
@@ -370,7 +370,7 @@ func newTrade(routes []*Swap, tradeType entities.TradeType) (*Trade, error) {
 	var poolAddressSet = make(map[common.Address]bool)
 	for _, route := range routes {
 		for _, pool := range route.Route.Pools {
-			addr, err := GetAddress(pool.Token0, pool.Token1, pool.Fee, "")
+			addr, err := pool.GetAddress()
 			if err != nil {
 				return nil, err
 			}
@@ -497,11 +497,10 @@ func BestTradeExactIn(pools []*Pool, currencyAmountIn *entities.CurrencyAmount,
 		}
 		amountOut, _, err := pool.GetOutputAmount(amountIn, nil)
 		if err != nil {
-			// TODO
-			// input too low
-			//  if (error.isInsufficientInputAmountError) {
-			// 	continue
-			//       }
+			// input too low or not enough liquidity in this pool
+			if isInsufficientError(err) {
+				continue
+			}
 			return nil, err
 		}
 		// we have arrived at the output token, so this is the final trade of one of the paths
@@ -576,11 +575,10 @@ func BestTradeExactOut(pools []*Pool, currencyIn entities.Currency, currencyAmou
 		}
 		amountIn, _, err := pool.GetInputAmount(amountOut, nil)
 		if err != nil {
-			// TODO
 			// not enough liquidity in this pool
-			// if error.isInsufficientReservesError {
-			// 	continue
-			// }
+			if isInsufficientError(err) {
+				continue
+			}
 			return nil, err
 		}
 		// we have arrived at the input token, so this is the final trade of one of the paths
@@ -612,6 +610,16 @@ func BestTradeExactOut(pools []*Pool, currencyIn entities.Currency, currencyAmou
 	return bestTrades, nil
 }
 
+// isInsufficientError returns true if the error means that the pool cannot fill the swap,
+// in which case the pool should be skipped by the route search
+func isInsufficientError(err error) bool {
+	var (
+		insufficientLiquidityErr   *InsufficientLiquidityError
+		insufficientInputAmountErr *InsufficientInputAmountError
+	)
+	return errors.As(err, &insufficientLiquidityErr) || errors.As(err, &insufficientInputAmountErr)
+}
+
 // sortedInsert given an array of items sorted by `comparator`, insert an item into its sort index and constrain the size to
 // `maxSize` by removing the last item
 func sortedInsert(items []*Trade, add *Trade, maxSize int, comparator func(a, b *Trade) int) ([]*Trade, error) {
@@ -468,12 +468,12 @@ func TestBestTradeExactIn(t *testing.T) {
 	assert.Equal(t, len(result), 1)
 	assert.Equal(t, len(result[0].Swaps[0].Route.Pools), 1)
 
-	// insufficient input for one pool
+	// insufficient input for one pool, the route through it is skipped
 	result, err = BestTradeExactIn([]*Pool{pool_0_1, pool_0_2, pool_1_2}, entities.FromRawAmount(token0, big.NewInt(1)), token2, nil, nil, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	assert.Equal(t, len(result), 2)
+	assert.Equal(t, len(result), 1)
 	assert.Equal(t, len(result[0].Swaps[0].Route.Pools), 1)
 	assert.Equal(t, result[0].Swaps[0].Route.TokenPath, []*entities.Token{token0, token2})
 	assert.True(t, result[0].OutputAmount().EqualTo(entities.FromRawAmount(token2, big.NewInt(1)).Fraction))
@@ -0,0 +1,230 @@
+package events
+
+import (
+	"errors"
+	"math/big"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/KyberNetwork/promm-sdk-go/abis"
+	"github.com/KyberNetwork/promm-sdk-go/entities"
+)
+
+var (
+	ErrUnknownEvent  = errors.New("unknown event")
+	ErrRemovedLog    = errors.New("log was removed")
+	ErrAmbiguousBurn = errors.New("burn of reinvestment tokens may be a logical burn")
+)
+
+// Event is a decoded event of a ProMM pool
+type Event interface {
+	// Apply returns the state of the pool after the event, the pool itself is not modified
+	Apply(pool *entities.Pool) (*entities.Pool, error)
+}
+
+// SwapEvent is emitted by the pool for every swap
+type SwapEvent struct {
+	Sender      common.Address
+	Recipient   common.Address
+	DeltaQty0   *big.Int // The amount of token0 received by the pool, negative when the pool sent it
+	DeltaQty1   *big.Int // The amount of token1 received by the pool, negative when the pool sent it
+	SqrtP       *big.Int // The sqrt price of the pool after the swap
+	Liquidity   *big.Int // The base liquidity of the pool after the swap
+	CurrentTick int      // The tick of the pool after the swap
+}
+
+// MintEvent is emitted by the pool when liquidity is added to a position
+type MintEvent struct {
+	Sender    common.Address
+	Owner     common.Address
+	TickLower int
+	TickUpper int
+	Qty       *big.Int // The amount of liquidity added
+	Qty0      *big.Int // The amount of token0 paid for the liquidity
+	Qty1      *big.Int // The amount of token1 paid for the liquidity
+}
+
+// BurnEvent is emitted by the pool when liquidity is removed from a position
+type BurnEvent struct {
+	Owner     common.Address
+	TickLower int
+	TickUpper int
+	Qty       *big.Int // The amount of liquidity removed
+	Qty0      *big.Int // The amount of token0 returned for the liquidity
+	Qty1      *big.Int // The amount of token1 returned for the liquidity
+}
+
+// BurnRTokensEvent is emitted by the pool when reinvestment tokens are burnt
+type BurnRTokensEvent struct {
+	Owner common.Address
+	Qty   *big.Int // The amount of reinvestment tokens burnt
+	Qty0  *big.Int // The amount of token0 returned for the reinvestment tokens
+	Qty1  *big.Int // The amount of token1 returned for the reinvestment tokens
+
+	// Whether the burn was a logical one, which only reduces the supply of reinvestment tokens. It is not part of the
+	// log, DecodeLog leaves it false and it must be set from the call to the pool, e.g. from a trace of the transaction
+	IsLogicalBurn bool
+}
+
+// FlashEvent is emitted by the pool for every flash loan
+type FlashEvent struct {
+	Sender    common.Address
+	Recipient common.Address
+	Qty0      *big.Int // The amount of token0 lent
+	Qty1      *big.Int // The amount of token1 lent
+	Paid0     *big.Int // The fee paid in token0
+	Paid1     *big.Int // The fee paid in token1
+}
+
+/**
+ * Decodes a log emitted by a ProMM pool. The address of the log is not checked, logs must be filtered by pool first
+ * @param log The log to decode
+ * @returns One of *SwapEvent, *MintEvent, *BurnEvent, *BurnRTokensEvent or *FlashEvent
+ */
+func DecodeLog(log types.Log) (Event, error) {
+	if len(log.Topics) == 0 {
+		return nil, ErrUnknownEvent
+	}
+	event, err := abis.PoolABI.EventByID(log.Topics[0])
+	if err != nil {
+		return nil, ErrUnknownEvent
+	}
+
+	values := make(map[string]interface{})
+	if err := abis.PoolABI.UnpackIntoMap(values, event.Name, log.Data); err != nil {
+		return nil, err
+	}
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+		return nil, err
+	}
+
+	switch event.Name {
+	case "Swap":
+		return &SwapEvent{
+			Sender:      values["sender"].(common.Address),
+			Recipient:   values["recipient"].(common.Address),
+			DeltaQty0:   values["deltaQty0"].(*big.Int),
+			DeltaQty1:   values["deltaQty1"].(*big.Int),
+			SqrtP:       values["sqrtP"].(*big.Int),
+			Liquidity:   values["liquidity"].(*big.Int),
+			CurrentTick: int(values["currentTick"].(*big.Int).Int64()),
+		}, nil
+	case "Mint":
+		return &MintEvent{
+			Sender:    values["sender"].(common.Address),
+			Owner:     values["owner"].(common.Address),
+			TickLower: int(values["tickLower"].(*big.Int).Int64()),
+			TickUpper: int(values["tickUpper"].(*big.Int).Int64()),
+			Qty:       values["qty"].(*big.Int),
+			Qty0:      values["qty0"].(*big.Int),
+			Qty1:      values["qty1"].(*big.Int),
+		}, nil
+	case "Burn":
+		return &BurnEvent{
+			Owner:     values["owner"].(common.Address),
+			TickLower: int(values["tickLower"].(*big.Int).Int64()),
+			TickUpper: int(values["tickUpper"].(*big.Int).Int64()),
+			Qty:       values["qty"].(*big.Int),
+			Qty0:      values["qty0"].(*big.Int),
+			Qty1:      values["qty1"].(*big.Int),
+		}, nil
+	case "BurnRTokens":
+		return &BurnRTokensEvent{
+			Owner: values["owner"].(common.Address),
+			Qty:   values["qty"].(*big.Int),
+			Qty0:  values["qty0"].(*big.Int),
+			Qty1:  values["qty1"].(*big.Int),
+		}, nil
+	case "Flash":
+		return &FlashEvent{
+			Sender:    values["sender"].(common.Address),
+			Recipient: values["recipient"].(common.Address),
+			Qty0:      values["qty0"].(*big.Int),
+			Qty1:      values["qty1"].(*big.Int),
+			Paid0:     values["paid0"].(*big.Int),
+			Paid1:     values["paid1"].(*big.Int),
+		}, nil
+	default:
+		return nil, ErrUnknownEvent
+	}
+}
+
+/**
+ * Decodes a log emitted by a ProMM pool and applies it to the pool. Burns of reinvestment tokens are applied as real
+ * burns, the ones that returned no tokens are rejected with ErrAmbiguousBurn as they may be logical burns, which the
+ * log doesn't tell apart: they must be decoded with DecodeLog and applied once their IsLogicalBurn is set
+ * @param pool The state of the pool before the log
+ * @param log The log to apply, logs removed by a reorg are rejected
+ * @returns The state of the pool after the log, the pool itself is not modified
+ */
+func ApplyLog(pool *entities.Pool, log types.Log) (*entities.Pool, error) {
+	if log.Removed {
+		return nil, ErrRemovedLog
+	}
+	event, err := DecodeLog(log)
+	if err != nil {
+		return nil, err
+	}
+	if burn, ok := event.(*BurnRTokensEvent); ok && burn.Qty0.Sign() == 0 && burn.Qty1.Sign() == 0 {
+		return nil, ErrAmbiguousBurn
+	}
+	return event.Apply(pool)
+}
+
+/**
+ * Applies the swap to the pool. The price, base liquidity and tick are taken from the event, the reinvestment
+ * liquidity and the tick crossings are simulated by swapping the input amount of the event, or by moving the price
+ * to the one of the event when the swap doesn't land on it, i.e. for exact output swaps, whose input amount is rounded
+ * up. The tick data of the pool must cover the swap, errors of the simulation, e.g. missing ticks, are returned
+ */
+func (e *SwapEvent) Apply(pool *entities.Pool) (*entities.Pool, error) {
+	next := pool
+	if e.SqrtP.Cmp(pool.SqrtRatioX96) != 0 {
+		inputAmount := core.FromRawAmount(pool.Token0, e.DeltaQty0)
+		if e.DeltaQty0.Sign() <= 0 {
+			inputAmount = core.FromRawAmount(pool.Token1, e.DeltaQty1)
+		}
+		result, err := pool.SimulateExactIn(inputAmount, nil)
+		if err != nil {
+			return nil, err
+		}
+		if result.Pool.SqrtRatioX96.Cmp(e.SqrtP) != 0 {
+			result, err = pool.AmountToReachSqrtPrice(e.SqrtP)
+			if err != nil {
+				return nil, err
+			}
+		}
+		next = result.Pool
+	}
+	return next.WithState(e.SqrtP, e.Liquidity, next.ReinvestLiquidity, e.CurrentTick, next.TickDataProvider)
+}
+
+// Apply adds the liquidity of the event to the pool and its ticks
+func (e *MintEvent) Apply(pool *entities.Pool) (*entities.Pool, error) {
+	return pool.Mint(e.TickLower, e.TickUpper, e.Qty)
+}
+
+// Apply removes the liquidity of the event from the pool and its ticks
+func (e *BurnEvent) Apply(pool *entities.Pool) (*entities.Pool, error) {
+	return pool.Burn(e.TickLower, e.TickUpper, e.Qty)
+}
+
+// Apply burns the reinvestment tokens of the event, as a logical burn if IsLogicalBurn is set. The pool must have its
+// reinvestment state set
+func (e *BurnRTokensEvent) Apply(pool *entities.Pool) (*entities.Pool, error) {
+	return pool.BurnRTokens(e.Qty, e.IsLogicalBurn)
+}
+
+// Apply returns the pool unchanged, the fees of flash loans are sent to the protocol fee recipient
+func (e *FlashEvent) Apply(pool *entities.Pool) (*entities.Pool, error) {
+	return pool, nil
+}
@@ -0,0 +1,238 @@
+package events
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/entities"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+var (
+	USDC = core.NewToken(1, common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"), 6, "USDC", "USD Coin")
+	DAI  = core.NewToken(1, common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F"), 18, "DAI", "DAI Stablecoin")
+
+	owner = common.HexToAddress("0x2B1Ad6184a6B0fac06bD225ed37C2AbC04415fF4")
+)
+
+// poolState is the state of a pool after a log, as read from the pool with getPoolState, getLiquidityState and
+// totalSupply at the block of the log
+type poolState struct {
+	SqrtP         *big.Int `json:"sqrtP"`
+	CurrentTick   int      `json:"currentTick"`
+	BaseL         *big.Int `json:"baseL"`
+	ReinvestL     *big.Int `json:"reinvestL"`
+	ReinvestLLast *big.Int `json:"reinvestLLast"`
+	RTotalSupply  *big.Int `json:"rTotalSupply"`
+}
+
+// logsFixture is a sequence of logs of a pool, with the state of the pool before the first log and after each log.
+// The source tells whether the logs are recorded on chain, or encoded with the pool ABI from a simulation of the pool,
+// in which case the states are the simulated ones and replaying the logs only checks the SDK against itself
+type logsFixture struct {
+	Source string                 `json:"source"`
+	Pool   *entities.PoolSnapshot `json:"pool"`
+	Logs   []struct {
+		Log       types.Log `json:"log"`
+		PoolState poolState `json:"poolState"`
+	} `json:"logs"`
+}
+
+// loadFixture reads the Mint, Swap, Flash, BurnRTokens and Burn logs of a pool starting at price 1
+func loadFixture(t *testing.T) *logsFixture {
+	data, err := os.ReadFile("testdata/logs.json")
+	assert.NoError(t, err)
+	var fixture logsFixture
+	assert.NoError(t, json.Unmarshal(data, &fixture))
+	if fixture.Source != "recorded" {
+		t.Logf("the logs of the fixture are %s, not recorded on chain", fixture.Source)
+	}
+	return &fixture
+}
+
+func loadLogs(t *testing.T) []types.Log {
+	fixture := loadFixture(t)
+	logs := make([]types.Log, len(fixture.Logs))
+	for i, entry := range fixture.Logs {
+		logs[i] = entry.Log
+	}
+	return logs
+}
+
+func newTestPool(t *testing.T) *entities.Pool {
+	pool, err := entities.NewPool(
+		USDC, DAI, constants.Fee01, utils.EncodeSqrtRatioX96(constants.One, constants.One), big.NewInt(0),
+		big.NewInt(100000), 0, nil,
+	)
+	assert.NoError(t, err)
+	pool.ReinvestLiquidityLast = big.NewInt(100000)
+	pool.RTotalSupply = big.NewInt(100000)
+	pool.FeeGrowthGlobal = big.NewInt(0)
+	return pool
+}
+
+func TestDecodeLog(t *testing.T) {
+	logs := loadLogs(t)
+
+	event, err := DecodeLog(logs[0])
+	assert.NoError(t, err)
+	mint, ok := event.(*MintEvent)
+	assert.True(t, ok)
+	assert.Equal(t, owner, mint.Owner)
+	assert.Equal(t, -600, mint.TickLower)
+	assert.Equal(t, 600, mint.TickUpper)
+	assert.Equal(t, "1000000000000000000", mint.Qty.String())
+
+	event, err = DecodeLog(logs[1])
+	assert.NoError(t, err)
+	swap, ok := event.(*SwapEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "10000000000000000", swap.DeltaQty1.String())
+	assert.Equal(t, -1, swap.DeltaQty0.Sign())
+	assert.Equal(t, "80020044039186705847797658614", swap.SqrtP.String())
+	assert.Equal(t, 198, swap.CurrentTick)
+
+	event, err = DecodeLog(logs[2])
+	assert.NoError(t, err)
+	flash, ok := event.(*FlashEvent)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(100), flash.Paid0)
+
+	event, err = DecodeLog(logs[3])
+	assert.NoError(t, err)
+	burnRTokens, ok := event.(*BurnRTokensEvent)
+	assert.True(t, ok)
+	assert.Equal(t, owner, burnRTokens.Owner)
+	assert.Equal(t, "4999975000123", burnRTokens.Qty.String())
+
+	event, err = DecodeLog(logs[4])
+	assert.NoError(t, err)
+	burn, ok := event.(*BurnEvent)
+	assert.True(t, ok)
+	assert.Equal(t, -600, burn.TickLower)
+	assert.Equal(t, "500000000000000000", burn.Qty.String())
+
+	unknown := logs[0]
+	unknown.Topics = []common.Hash{common.HexToHash("0x01")}
+	_, err = DecodeLog(unknown)
+	assert.ErrorIs(t, err, ErrUnknownEvent)
+	_, err = DecodeLog(types.Log{})
+	assert.ErrorIs(t, err, ErrUnknownEvent)
+}
+
+func TestApplyLog(t *testing.T) {
+	logs := loadLogs(t)
+	pool := newTestPool(t)
+
+	// mint
+	pool, err := ApplyLog(pool, logs[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "1000000000000000000", pool.Liquidity.String())
+
+	// swap
+	pool, err = ApplyLog(pool, logs[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "80020044039186705847797658614", pool.SqrtRatioX96.String())
+	assert.Equal(t, 198, pool.TickCurrent)
+	assert.Equal(t, "5000000100000", pool.ReinvestLiquidity.String())
+
+	// flash
+	flashed, err := ApplyLog(pool, logs[2])
+	assert.NoError(t, err)
+	assert.Equal(t, pool, flashed)
+
+	// burn rTokens
+	pool, err = ApplyLog(pool, logs[3])
+	assert.NoError(t, err)
+	assert.Equal(t, "100002", pool.ReinvestLiquidity.String())
+	assert.Equal(t, pool.ReinvestLiquidity, pool.ReinvestLiquidityLast)
+	assert.Equal(t, "100001", pool.RTotalSupply.String())
+
+	// burn
+	pool, err = ApplyLog(pool, logs[4])
+	assert.NoError(t, err)
+	assert.Equal(t, "500000000000000000", pool.Liquidity.String())
+	tick, err := pool.TickDataProvider.GetTick(600)
+	assert.NoError(t, err)
+	assert.Equal(t, "500000000000000000", tick.LiquidityGross.String())
+	assert.Equal(t, "-500000000000000000", tick.LiquidityNet.String())
+
+	// the log of a burn that returned no tokens doesn't tell whether it was a logical burn
+	ambiguous := logs[3]
+	ambiguous.Data = append(append([]byte{}, ambiguous.Data[:32]...), make([]byte, 64)...)
+	_, err = ApplyLog(pool, ambiguous)
+	assert.ErrorIs(t, err, ErrAmbiguousBurn)
+
+	removed := logs[4]
+	removed.Removed = true
+	_, err = ApplyLog(pool, removed)
+	assert.ErrorIs(t, err, ErrRemovedLog)
+}
+
+func TestApplyLog_Replay(t *testing.T) {
+	fixture := loadFixture(t)
+	pool, err := fixture.Pool.ToPool()
+	assert.NoError(t, err)
+
+	for i, entry := range fixture.Logs {
+		pool, err = ApplyLog(pool, entry.Log)
+		assert.NoError(t, err, "log %d", i)
+		state := entry.PoolState
+		assert.Equal(t, state.SqrtP.String(), pool.SqrtRatioX96.String(), "log %d", i)
+		assert.Equal(t, state.CurrentTick, pool.TickCurrent, "log %d", i)
+		assert.Equal(t, state.BaseL.String(), pool.Liquidity.String(), "log %d", i)
+		assert.Equal(t, state.ReinvestL.String(), pool.ReinvestLiquidity.String(), "log %d", i)
+		assert.Equal(t, state.ReinvestLLast.String(), pool.ReinvestLiquidityLast.String(), "log %d", i)
+		assert.Equal(t, state.RTotalSupply.String(), pool.RTotalSupply.String(), "log %d", i)
+	}
+}
+
+func TestBurnRTokensEvent_Apply(t *testing.T) {
+	pool := newTestPool(t)
+
+	// a logical burn only reduces the supply
+	event := &BurnRTokensEvent{
+		Owner: owner, Qty: big.NewInt(10), Qty0: big.NewInt(0), Qty1: big.NewInt(0), IsLogicalBurn: true,
+	}
+	burnt, err := event.Apply(pool)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(99990), burnt.RTotalSupply)
+	assert.Equal(t, pool.ReinvestLiquidity, burnt.ReinvestLiquidity)
+
+	// a real burn whose returned amounts round to zero still removes its share of the reinvestment liquidity
+	event.IsLogicalBurn = false
+	burnt, err = event.Apply(pool)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(99990), burnt.RTotalSupply)
+	assert.Equal(t, big.NewInt(99990), burnt.ReinvestLiquidity)
+
+	pool.RTotalSupply = nil
+	_, err = event.Apply(pool)
+	assert.ErrorIs(t, err, entities.ErrReinvestmentStateUnknown)
+}
+
+func TestSwapEvent_Apply_SimulationError(t *testing.T) {
+	// the price of the event is within the ticks known locally, which cannot absorb the input of the swap
+	pool, err := newTestPool(t).Mint(-600, 600, big.NewInt(1000))
+	assert.NoError(t, err)
+	sqrtP, err := utils.GetSqrtRatioAtTick(300)
+	assert.NoError(t, err)
+	event := &SwapEvent{
+		DeltaQty0:   big.NewInt(-1),
+		DeltaQty1:   big.NewInt(1000000),
+		SqrtP:       sqrtP,
+		Liquidity:   big.NewInt(1000),
+		CurrentTick: 300,
+	}
+	_, err = event.Apply(pool)
+	var liquidityErr *entities.InsufficientLiquidityError
+	assert.ErrorAs(t, err, &liquidityErr)
+}
@@ -0,0 +1,571 @@
+package periphery
+
+import (
+	_ "embed"
+	"math/big"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/entities"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+//go:embed contracts/interfaces/IBasePositionManager.sol/IBasePositionManager.json
+var basePositionManagerABI []byte
+
+// getBasePositionManagerABI returns the ABI of the ProMM position manager, whose functions differ from the Uniswap V3 one
+func getBasePositionManagerABI() abi.ABI {
+	return GetABI(basePositionManagerABI)
+}
+
+type ProMMMintParams struct {
+	Token0         common.Address
+	Token1         common.Address
+	Fee            *big.Int
+	TickLower      *big.Int
+	TickUpper      *big.Int
+	TicksPrevious  [2]*big.Int
+	Amount0Desired *big.Int
+	Amount1Desired *big.Int
+	Amount0Min     *big.Int
+	Amount1Min     *big.Int
+	Recipient      common.Address
+	Deadline       *big.Int
+}
+
+func encodeCreateAndUnlock(pool *entities.Pool) ([]byte, error) {
+	abi := getBasePositionManagerABI()
+	return abi.Pack("createAndUnlockPoolIfNecessary", pool.Token0.Address, pool.Token1.Address, big.NewInt(int64(pool.Fee)), pool.SqrtRatioX96)
+}
+
+/**
+ * Produces the calldata for minting a position with the ProMM position manager. The ticksPrevious hints of the mint
+ * are derived from the tick data provider of the pool of the position, which must hold the current initialized ticks
+ * of the pool, otherwise the mint can revert
+ * @param position The position to mint
+ * @param opts Additional information necessary for generating the calldata
+ * @returns The call parameters
+ */
+func MintCallParameters(position *entities.Position, opts *MintOptions) (*utils.MethodParameters, error) {
+	if position.Liquidity.Cmp(constants.Zero) <= 0 {
+		return nil, ErrZeroLiquidity
+	}
+
+	var calldatas [][]byte
+
+	// get amounts
+	amount0Desired, amount1Desired, err := position.MintAmounts()
+	if err != nil {
+		return nil, err
+	}
+
+	// adjust for slippage
+	amount0Min, amount1Min, err := position.MintAmountsWithSlippage(opts.SlippageTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	ticksPrevious, err := position.TicksPrevious()
+	if err != nil {
+		return nil, err
+	}
+
+	// create pool if needed
+	if opts.CreatePool {
+		calldata, err := encodeCreateAndUnlock(position.Pool)
+		if err != nil {
+			return nil, err
+		}
+		calldatas = append(calldatas, calldata)
+	}
+
+	// permits if necessary
+	if opts.Token0Permit != nil {
+		calldata, err := EncodePermit(position.Pool.Token0, opts.Token0Permit)
+		if err != nil {
+			return nil, err
+		}
+		calldatas = append(calldatas, calldata)
+	}
+	if opts.Token1Permit != nil {
+		calldata, err := EncodePermit(position.Pool.Token1, opts.Token1Permit)
+		if err != nil {
+			return nil, err
+		}
+		calldatas = append(calldatas, calldata)
+	}
+
+	// mint
+	abi := getBasePositionManagerABI()
+	calldata, err := abi.Pack("mint", &ProMMMintParams{
+		Token0:         position.Pool.Token0.Address,
+		Token1:         position.Pool.Token1.Address,
+		Fee:            big.NewInt(int64(position.Pool.Fee)),
+		TickLower:      big.NewInt(int64(position.TickLower)),
+		TickUpper:      big.NewInt(int64(position.TickUpper)),
+		TicksPrevious:  [2]*big.Int{big.NewInt(int64(ticksPrevious[0])), big.NewInt(int64(ticksPrevious[1]))},
+		Amount0Desired: amount0Desired,
+		Amount1Desired: amount1Desired,
+		Amount0Min:     amount0Min,
+		Amount1Min:     amount1Min,
+		Recipient:      opts.Recipient,
+		Deadline:       opts.Deadline,
+	})
+	if err != nil {
+		return nil, err
+	}
+	calldatas = append(calldatas, calldata)
+
+	value := constants.Zero
+	if opts.UseNative != nil {
+		wrapped, err := wrappedToken(opts.UseNative)
+		if err != nil {
+			return nil, err
+		}
+		if !position.Pool.Token0.Equal(wrapped) && !position.Pool.Token1.Equal(wrapped) {
+			return nil, ErrNoWETH
+		}
+
+		if position.Pool.Token0.Equal(wrapped) {
+			value = amount0Desired
+		} else {
+			value = amount1Desired
+		}
+
+		// we only need to refund if we're actually sending ETH
+		if value.Cmp(constants.Zero) > 0 {
+			calldata, err := abi.Pack("refundEth")
+			if err != nil {
+				return nil, err
+			}
+			calldatas = append(calldatas, calldata)
+		}
+	}
+
+	datas, err := EncodeMulticall(calldatas)
+	if err != nil {
+		return nil, err
+	}
+
+	to := contractAddress(position.Pool.ChainID(), positionManagerOf)
+	return &utils.MethodParameters{
+		Calldata: datas,
+		Value:    value,
+		To:       to,
+		ChainID:  position.Pool.ChainID(),
+	}, nil
+}
+
+type ProMMIncreaseLiquidityParams struct {
+	TokenId        *big.Int
+	Amount0Desired *big.Int
+	Amount1Desired *big.Int
+	Amount0Min     *big.Int
+	Amount1Min     *big.Int
+	Deadline       *big.Int
+}
+
+type ProMMRemoveLiquidityParams struct {
+	TokenId    *big.Int
+	Liquidity  *big.Int
+	Amount0Min *big.Int
+	Amount1Min *big.Int
+	Deadline   *big.Int
+}
+
+type ProMMBurnRTokenParams struct {
+	TokenId    *big.Int
+	Amount0Min *big.Int
+	Amount1Min *big.Int
+	Deadline   *big.Int
+}
+
+// Options for producing the calldata to send the tokens held by the ProMM position manager for a position
+type ProMMCollectOptions struct {
+	ExpectedCurrencyOwed0 *core.CurrencyAmount // Expected amount of token0 held by the position manager, ether is unwrapped from WETH
+	ExpectedCurrencyOwed1 *core.CurrencyAmount // Expected amount of token1 held by the position manager, ether is unwrapped from WETH
+	Recipient             common.Address       // The account that should receive the tokens
+}
+
+// Options for producing the calldata to burn the reinvestment tokens owed to a position
+type BurnRTokensOptions struct {
+	TokenID           *big.Int               // The ID of the position to burn the reinvestment tokens of
+	Fees              *entities.PositionFees // The fees owed to the position, from Position.FeesOwed
+	SlippageTolerance *core.Percent          // How much the amounts the reinvestment tokens are burnt for are allowed to move
+	Deadline          *big.Int               // When the transaction expires, in epoch seconds
+	CollectOptions    *ProMMCollectOptions   // Parameters to be passed on to transferAllTokens, the burnt amounts are added to the expected amounts
+}
+
+// Options for producing the calldata to exit a position with the ProMM position manager
+type ProMMRemoveLiquidityOptions struct {
+	TokenID             *big.Int               // The ID of the token to exit
+	LiquidityPercentage *core.Percent          // The percentage of position liquidity to exit
+	SlippageTolerance   *core.Percent          // How much the pool price is allowed to move
+	Deadline            *big.Int               // When the transaction expires, in epoch seconds.
+	BurnToken           bool                   // Whether the NFT should be burned if the entire position is being exited, by default false
+	Permit              *NFTPermitOptions      // The optional permit of the token ID being exited, in case the exit transaction is being sent by an account that does not own the NFT
+	Fees                *entities.PositionFees // The optional fees owed to the position, from Position.FeesOwed. If set, the reinvestment tokens owed to the position are burnt in the same multicall
+	CollectOptions      *ProMMCollectOptions   // Parameters to be passed on to transferAllTokens
+}
+
+/**
+ * Produces the calldata for adding liquidity to an existing position with the ProMM position manager
+ * @param position The liquidity to add, in the range of the position
+ * @param opts Additional information necessary for generating the calldata
+ * @returns The call parameters
+ */
+func AddLiquidityCallParameters(position *entities.Position, opts *IncreaseOptions) (*utils.MethodParameters, error) {
+	if position.Liquidity.Cmp(constants.Zero) <= 0 {
+		return nil, ErrZeroLiquidity
+	}
+
+	var calldatas [][]byte
+
+	// get amounts
+	amount0Desired, amount1Desired, err := position.MintAmounts()
+	if err != nil {
+		return nil, err
+	}
+
+	// adjust for slippage
+	amount0Min, amount1Min, err := position.MintAmountsWithSlippage(opts.SlippageTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	// permits if necessary
+	if opts.Token0Permit != nil {
+		calldata, err := EncodePermit(position.Pool.Token0, opts.Token0Permit)
+		if err != nil {
+			return nil, err
+		}
+		calldatas = append(calldatas, calldata)
+	}
+	if opts.Token1Permit != nil {
+		calldata, err := EncodePermit(position.Pool.Token1, opts.Token1Permit)
+		if err != nil {
+			return nil, err
+		}
+		calldatas = append(calldatas, calldata)
+	}
+
+	// add liquidity
+	abi := getBasePositionManagerABI()
+	calldata, err := abi.Pack("addLiquidity", &ProMMIncreaseLiquidityParams{
+		TokenId:        opts.TokenID,
+		Amount0Desired: amount0Desired,
+		Amount1Desired: amount1Desired,
+		Amount0Min:     amount0Min,
+		Amount1Min:     amount1Min,
+		Deadline:       opts.Deadline,
+	})
+	if err != nil {
+		return nil, err
+	}
+	calldatas = append(calldatas, calldata)
+
+	value := constants.Zero
+	if opts.UseNative != nil {
+		wrapped, err := wrappedToken(opts.UseNative)
+		if err != nil {
+			return nil, err
+		}
+		if !position.Pool.Token0.Equal(wrapped) && !position.Pool.Token1.Equal(wrapped) {
+			return nil, ErrNoWETH
+		}
+
+		if position.Pool.Token0.Equal(wrapped) {
+			value = amount0Desired
+		} else {
+			value = amount1Desired
+		}
+
+		// we only need to refund if we're actually sending ETH
+		if value.Cmp(constants.Zero) > 0 {
+			calldata, err := abi.Pack("refundEth")
+			if err != nil {
+				return nil, err
+			}
+			calldatas = append(calldatas, calldata)
+		}
+	}
+
+	datas, err := EncodeMulticall(calldatas)
+	if err != nil {
+		return nil, err
+	}
+
+	to := contractAddress(position.Pool.ChainID(), positionManagerOf)
+	return &utils.MethodParameters{
+		Calldata: datas,
+		Value:    value,
+		To:       to,
+		ChainID:  position.Pool.ChainID(),
+	}, nil
+}
+
+/**
+ * Produces the calldata for completely or partially exiting a position with the ProMM position manager. The removed
+ * tokens, and the tokens the reinvestment tokens are burnt for, are held by the position manager until they are
+ * transferred to the recipient of the collect options
+ * @param position The position to exit
+ * @param opts Additional information necessary for generating the calldata
+ * @returns The call parameters
+ */
+func RemoveLiquidityCallParameters(position *entities.Position, opts *ProMMRemoveLiquidityOptions) (*utils.MethodParameters, error) {
+	var calldatas [][]byte
+
+	// construct a partial position with a percentage of liquidity
+	partialPosition, err := entities.NewPosition(
+		position.Pool,
+		opts.LiquidityPercentage.Multiply(core.NewPercent(position.Liquidity, big.NewInt(1))).Quotient(),
+		position.TickLower,
+		position.TickUpper,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if partialPosition.Liquidity.Cmp(constants.Zero) <= 0 {
+		return nil, ErrZeroLiquidity
+	}
+
+	// slippage-adjusted underlying amounts
+	amount0Min, amount1Min, err := partialPosition.BurnAmountsWithSlippage(opts.SlippageTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	abi := getBasePositionManagerABI()
+	if opts.Permit != nil {
+		calldata, err := encodeNFTPermit(opts.TokenID, opts.Permit)
+		if err != nil {
+			return nil, err
+		}
+		calldatas = append(calldatas, calldata)
+	}
+
+	// remove liquidity
+	calldata, err := abi.Pack("removeLiquidity", &ProMMRemoveLiquidityParams{
+		TokenId:    opts.TokenID,
+		Liquidity:  partialPosition.Liquidity,
+		Amount0Min: amount0Min,
+		Amount1Min: amount1Min,
+		Deadline:   opts.Deadline,
+	})
+	if err != nil {
+		return nil, err
+	}
+	calldatas = append(calldatas, calldata)
+
+	collectOpts := &ProMMCollectOptions{
+		// add the underlying value to the expected currency already owed
+		ExpectedCurrencyOwed0: opts.CollectOptions.ExpectedCurrencyOwed0.Add(core.FromRawAmount(opts.CollectOptions.ExpectedCurrencyOwed0.Currency, amount0Min)),
+		ExpectedCurrencyOwed1: opts.CollectOptions.ExpectedCurrencyOwed1.Add(core.FromRawAmount(opts.CollectOptions.ExpectedCurrencyOwed1.Currency, amount1Min)),
+		Recipient:             opts.CollectOptions.Recipient,
+	}
+
+	// burn the reinvestment tokens owed to the position, including the ones the removal syncs
+	if opts.Fees != nil {
+		calldata, expectedOwed, err := encodeBurnRTokens(&BurnRTokensOptions{
+			TokenID:           opts.TokenID,
+			Fees:              opts.Fees,
+			SlippageTolerance: opts.SlippageTolerance,
+			Deadline:          opts.Deadline,
+			CollectOptions:    collectOpts,
+		})
+		if err != nil {
+			return nil, err
+		}
+		calldatas = append(calldatas, calldata)
+		collectOpts = expectedOwed
+	}
+
+	transferdata, err := encodeTransferAllTokens(collectOpts)
+	if err != nil {
+		return nil, err
+	}
+	calldatas = append(calldatas, transferdata...)
+
+	if opts.LiquidityPercentage.EqualTo(core.NewFraction(constants.One, big.NewInt(1))) {
+		if opts.BurnToken {
+			calldata, err := abi.Pack("burn", opts.TokenID)
+			if err != nil {
+				return nil, err
+			}
+			calldatas = append(calldatas, calldata)
+		}
+	} else {
+		if opts.BurnToken {
+			return nil, ErrCannotBurn
+		}
+	}
+
+	data, err := EncodeMulticall(calldatas)
+	if err != nil {
+		return nil, err
+	}
+	to := contractAddress(position.Pool.ChainID(), positionManagerOf)
+	return &utils.MethodParameters{
+		Calldata: data,
+		Value:    constants.Zero,
+		To:       to,
+		ChainID:  position.Pool.ChainID(),
+	}, nil
+}
+
+/**
+ * Produces the calldata for burning the reinvestment tokens owed to a position into the underlying tokens, and
+ * transferring them from the ProMM position manager to the recipient
+ * @param opts Additional information necessary for generating the calldata
+ * @returns The call parameters
+ */
+func BurnRTokensCallParameters(opts *BurnRTokensOptions) (*utils.MethodParameters, error) {
+	calldata, collectOpts, err := encodeBurnRTokens(opts)
+	if err != nil {
+		return nil, err
+	}
+	calldatas := [][]byte{calldata}
+
+	transferdata, err := encodeTransferAllTokens(collectOpts)
+	if err != nil {
+		return nil, err
+	}
+	calldatas = append(calldatas, transferdata...)
+
+	data, err := EncodeMulticall(calldatas)
+	if err != nil {
+		return nil, err
+	}
+	to := contractAddress(opts.Fees.Amount0.Currency.ChainId(), positionManagerOf)
+	return &utils.MethodParameters{
+		Calldata: data,
+		Value:    constants.Zero,
+		To:       to,
+		ChainID:  opts.Fees.Amount0.Currency.ChainId(),
+	}, nil
+}
+
+/**
+ * Produces the calldata for syncing the fee growth of a position, which converts the fees earned by the position
+ * into reinvestment tokens owed to it
+ * @param tokenID The ID of the position
+ * @returns The call parameters, without a target as the token ID does not tell the chain of the position
+ */
+func SyncFeeGrowthCallParameters(tokenID *big.Int) (*utils.MethodParameters, error) {
+	abi := getBasePositionManagerABI()
+	calldata, err := abi.Pack("syncFeeGrowth", tokenID)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.MethodParameters{
+		Calldata: calldata,
+		Value:    constants.Zero,
+	}, nil
+}
+
+/**
+ * Produces the calldata for syncing the fee growth of a position, targeting the position manager of a chain
+ * @param tokenID The ID of the position
+ * @param chainID The chain ID of the position manager
+ * @returns The call parameters
+ */
+func SyncFeeGrowthCallParametersWithChainID(tokenID *big.Int, chainID uint) (*utils.MethodParameters, error) {
+	params, err := SyncFeeGrowthCallParameters(tokenID)
+	if err != nil {
+		return nil, err
+	}
+	params.To = contractAddress(chainID, positionManagerOf)
+	params.ChainID = chainID
+	return params, nil
+}
+
+/**
+ * Produces the calldata for transferring the tokens held by the ProMM position manager to the recipient
+ * @param opts Additional information necessary for generating the calldata
+ * @returns The call parameters
+ */
+func TransferAllTokensCallParameters(opts *ProMMCollectOptions) (*utils.MethodParameters, error) {
+	calldatas, err := encodeTransferAllTokens(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := EncodeMulticall(calldatas)
+	if err != nil {
+		return nil, err
+	}
+	to := contractAddress(opts.ExpectedCurrencyOwed0.Currency.ChainId(), positionManagerOf)
+	return &utils.MethodParameters{
+		Calldata: data,
+		Value:    constants.Zero,
+		To:       to,
+		ChainID:  opts.ExpectedCurrencyOwed0.Currency.ChainId(),
+	}, nil
+}
+
+// encodeBurnRTokens returns the calldata of burnRTokens, and the collect options with the burnt amounts added
+func encodeBurnRTokens(opts *BurnRTokensOptions) ([]byte, *ProMMCollectOptions, error) {
+	amount0Min := amountWithSlippage(opts.Fees.Amount0.Quotient(), opts.SlippageTolerance)
+	amount1Min := amountWithSlippage(opts.Fees.Amount1.Quotient(), opts.SlippageTolerance)
+
+	abi := getBasePositionManagerABI()
+	calldata, err := abi.Pack("burnRTokens", &ProMMBurnRTokenParams{
+		TokenId:    opts.TokenID,
+		Amount0Min: amount0Min,
+		Amount1Min: amount1Min,
+		Deadline:   opts.Deadline,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return calldata, &ProMMCollectOptions{
+		ExpectedCurrencyOwed0: opts.CollectOptions.ExpectedCurrencyOwed0.Add(core.FromRawAmount(opts.CollectOptions.ExpectedCurrencyOwed0.Currency, amount0Min)),
+		ExpectedCurrencyOwed1: opts.CollectOptions.ExpectedCurrencyOwed1.Add(core.FromRawAmount(opts.CollectOptions.ExpectedCurrencyOwed1.Currency, amount1Min)),
+		Recipient:             opts.CollectOptions.Recipient,
+	}, nil
+}
+
+func encodeTransferAllTokens(opts *ProMMCollectOptions) ([][]byte, error) {
+	var calldatas [][]byte
+
+	abi := getBasePositionManagerABI()
+	for _, owed := range []*core.CurrencyAmount{opts.ExpectedCurrencyOwed0, opts.ExpectedCurrencyOwed1} {
+		var (
+			calldata []byte
+			err      error
+		)
+		if owed.Currency.IsNative() {
+			calldata, err = abi.Pack("unwrapWeth", owed.Quotient(), opts.Recipient)
+		} else {
+			var token *core.Token
+			token, err = wrappedToken(owed.Currency)
+			if err != nil {
+				return nil, err
+			}
+			calldata, err = abi.Pack("transferAllTokens", token.Address, owed.Quotient(), opts.Recipient)
+		}
+		if err != nil {
+			return nil, err
+		}
+		calldatas = append(calldatas, calldata)
+	}
+
+	return calldatas, nil
+}
+
+func encodeNFTPermit(tokenID *big.Int, permit *NFTPermitOptions) ([]byte, error) {
+	abi := getBasePositionManagerABI()
+	return abi.Pack("permit", permit.Spender, tokenID, permit.Deadline, permit.V, permit.R, permit.S)
+}
+
+// amountWithSlippage returns the amount reduced by the slippage tolerance, rounded down
+func amountWithSlippage(amount *big.Int, slippageTolerance *core.Percent) *big.Int {
+	reduced := new(big.Int).Sub(slippageTolerance.Denominator, slippageTolerance.Numerator)
+	reduced.Mul(reduced, amount)
+	return reduced.Div(reduced, slippageTolerance.Denominator)
+}
@@ -0,0 +1,313 @@
+package periphery
+
+import (
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/entities"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+func TestMintCallParameters(t *testing.T) {
+	positionManagerABI := getBasePositionManagerABI()
+	tickSpacing := constants.TickSpacings[feeT]
+	opts := &MintOptions{
+		CommonAddLiquidityOptions: &CommonAddLiquidityOptions{
+			SlippageTolerance: slippageToleranceT,
+			Deadline:          deadlineT,
+		},
+		MintSpecificOptions: &MintSpecificOptions{
+			Recipient: recipientT,
+		},
+	}
+
+	// throws if liquidity is 0
+	pos, err := entities.NewPosition(pool01T, big.NewInt(0), -tickSpacing, tickSpacing)
+	assert.NoError(t, err)
+	_, err = MintCallParameters(pos, opts)
+	assert.ErrorIs(t, err, ErrZeroLiquidity)
+
+	// the ticks previous of a pool without ticks are the head of the list
+	pos, err = entities.NewPosition(pool01T, big.NewInt(1), -tickSpacing, tickSpacing)
+	assert.NoError(t, err)
+	params, err := MintCallParameters(pos, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xea540632000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000028fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff80000000000000000000000000000000000000000000000000000000000000008fffffffffffffffffffffffffffffffffffffffffffffffffffffffffff27618fffffffffffffffffffffffffffffffffffffffffffffffffffffffffff2761800000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000003000000000000000000000000000000000000000000000000000000000000007b", hexutil.Encode(params.Calldata))
+	assert.Equal(t, "0x00", utils.ToHex(params.Value))
+
+	// the ticks previous are the initialized ticks below the ticks of the position
+	pool := makePool(token0, token1)
+	pos, err = entities.NewPosition(pool, big.NewInt(1), -tickSpacing, tickSpacing)
+	assert.NoError(t, err)
+	params, err = MintCallParameters(pos, opts)
+	assert.NoError(t, err)
+	values, err := positionManagerABI.Methods["mint"].Inputs.Unpack(params.Calldata[4:])
+	assert.NoError(t, err)
+	mintParams := abi.ConvertType(values[0], new(ProMMMintParams)).(*ProMMMintParams)
+	lowest := int64(ticks[0].Index)
+	assert.Equal(t, []int64{lowest, lowest}, []int64{mintParams.TicksPrevious[0].Int64(), mintParams.TicksPrevious[1].Int64()})
+
+	// creates the pool and refunds ether
+	pos, err = entities.NewPosition(pool1wethT, big.NewInt(1), -tickSpacing, tickSpacing)
+	assert.NoError(t, err)
+	params, err = MintCallParameters(pos, &MintOptions{
+		CommonAddLiquidityOptions: &CommonAddLiquidityOptions{
+			SlippageTolerance: slippageToleranceT,
+			Deadline:          deadlineT,
+			UseNative:         core.EtherOnChain(1),
+		},
+		MintSpecificOptions: &MintSpecificOptions{
+			Recipient:  recipientT,
+			CreatePool: true,
+		},
+	})
+	assert.NoError(t, err)
+	values, err = positionManagerABI.Methods["multicall"].Inputs.Unpack(params.Calldata[4:])
+	assert.NoError(t, err)
+	calldatas := values[0].([][]byte)
+	assert.Len(t, calldatas, 3)
+	assert.Equal(t, positionManagerABI.Methods["createAndUnlockPoolIfNecessary"].ID, calldatas[0][:4])
+	assert.Equal(t, positionManagerABI.Methods["mint"].ID, calldatas[1][:4])
+	assert.Equal(t, positionManagerABI.Methods["refundEth"].ID, calldatas[2])
+	assert.Equal(t, "0x01", utils.ToHex(params.Value))
+}
+
+// decodeBasePositionManagerCalls returns the methods and arguments of the calls of a position manager multicall
+func decodeBasePositionManagerCalls(t *testing.T, calldata []byte) ([]string, [][]interface{}) {
+	positionManagerABI := getBasePositionManagerABI()
+	calldatas := [][]byte{calldata}
+	if method, err := positionManagerABI.MethodById(calldata[:4]); err == nil && method.Name == "multicall" {
+		values, err := method.Inputs.Unpack(calldata[4:])
+		assert.NoError(t, err)
+		calldatas = values[0].([][]byte)
+	}
+
+	var (
+		names []string
+		args  [][]interface{}
+	)
+	for _, calldata := range calldatas {
+		method, err := positionManagerABI.MethodById(calldata[:4])
+		assert.NoError(t, err)
+		values, err := method.Inputs.Unpack(calldata[4:])
+		assert.NoError(t, err)
+		names = append(names, method.Name)
+		args = append(args, values)
+	}
+	return names, args
+}
+
+func TestAddLiquidityCallParameters(t *testing.T) {
+	tickSpacing := constants.TickSpacings[feeT]
+	opts := &IncreaseOptions{
+		CommonAddLiquidityOptions: &CommonAddLiquidityOptions{
+			SlippageTolerance: slippageToleranceT,
+			Deadline:          deadlineT,
+		},
+		IncreaseSpecificOptions: &IncreaseSpecificOptions{
+			TokenID: tokenIDT,
+		},
+	}
+
+	// throws if liquidity is 0
+	pos, err := entities.NewPosition(pool01T, big.NewInt(0), -tickSpacing, tickSpacing)
+	assert.NoError(t, err)
+	_, err = AddLiquidityCallParameters(pos, opts)
+	assert.ErrorIs(t, err, ErrZeroLiquidity)
+
+	// succeeds
+	pos, err = entities.NewPosition(pool01T, big.NewInt(1), -tickSpacing, tickSpacing)
+	assert.NoError(t, err)
+	params, err := AddLiquidityCallParameters(pos, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x2f45d9b100000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000007b", hexutil.Encode(params.Calldata))
+	assert.Equal(t, "0x00", utils.ToHex(params.Value))
+
+	// refunds ether
+	pos, err = entities.NewPosition(pool1wethT, big.NewInt(1), -tickSpacing, tickSpacing)
+	assert.NoError(t, err)
+	opts.UseNative = core.EtherOnChain(1)
+	params, err = AddLiquidityCallParameters(pos, opts)
+	assert.NoError(t, err)
+	names, _ := decodeBasePositionManagerCalls(t, params.Calldata)
+	assert.Equal(t, []string{"addLiquidity", "refundEth"}, names)
+	assert.Equal(t, "0x01", utils.ToHex(params.Value))
+}
+
+func TestRemoveLiquidityCallParameters(t *testing.T) {
+	tickSpacing := constants.TickSpacings[feeT]
+	collectOpts := &ProMMCollectOptions{
+		ExpectedCurrencyOwed0: core.FromRawAmount(token0T, big.NewInt(0)),
+		ExpectedCurrencyOwed1: core.FromRawAmount(token1T, big.NewInt(0)),
+		Recipient:             recipientT,
+	}
+
+	// throws for bad burn
+	pos, err := entities.NewPosition(pool01T, big.NewInt(50), -tickSpacing, tickSpacing)
+	assert.NoError(t, err)
+	_, err = RemoveLiquidityCallParameters(pos, &ProMMRemoveLiquidityOptions{
+		TokenID:             tokenIDT,
+		LiquidityPercentage: core.NewPercent(big.NewInt(99), big.NewInt(100)),
+		SlippageTolerance:   slippageToleranceT,
+		Deadline:            deadlineT,
+		BurnToken:           true,
+		CollectOptions:      collectOpts,
+	})
+	assert.ErrorIs(t, err, ErrCannotBurn)
+
+	// throws for 0 liquidity from small percentage
+	_, err = RemoveLiquidityCallParameters(pos, &ProMMRemoveLiquidityOptions{
+		TokenID:             tokenIDT,
+		LiquidityPercentage: core.NewPercent(big.NewInt(1), big.NewInt(100)),
+		SlippageTolerance:   slippageToleranceT,
+		Deadline:            deadlineT,
+		CollectOptions:      collectOpts,
+	})
+	assert.ErrorIs(t, err, ErrZeroLiquidity)
+
+	// removes liquidity and transfers the tokens
+	pos, err = entities.NewPosition(pool01T, big.NewInt(100), -tickSpacing, tickSpacing)
+	assert.NoError(t, err)
+	params, err := RemoveLiquidityCallParameters(pos, &ProMMRemoveLiquidityOptions{
+		TokenID:             tokenIDT,
+		LiquidityPercentage: core.NewPercent(big.NewInt(1), big.NewInt(2)),
+		SlippageTolerance:   slippageToleranceT,
+		Deadline:            deadlineT,
+		CollectOptions:      collectOpts,
+	})
+	assert.NoError(t, err)
+	names, args := decodeBasePositionManagerCalls(t, params.Calldata)
+	assert.Equal(t, []string{"removeLiquidity", "transferAllTokens", "transferAllTokens"}, names)
+	removeParams := args[0][0].(struct {
+		TokenId    *big.Int `json:"tokenId"`
+		Liquidity  *big.Int `json:"liquidity"`
+		Amount0Min *big.Int `json:"amount0Min"`
+		Amount1Min *big.Int `json:"amount1Min"`
+		Deadline   *big.Int `json:"deadline"`
+	})
+	assert.Equal(t, big.NewInt(50), removeParams.Liquidity)
+	assert.Equal(t, []interface{}{token0T.Address, removeParams.Amount0Min, recipientT}, args[1])
+	assert.Equal(t, []interface{}{token1T.Address, removeParams.Amount1Min, recipientT}, args[2])
+	assert.Equal(t, "0x00", utils.ToHex(params.Value))
+
+	// burns the reinvestment tokens and the position
+	fees := &entities.PositionFees{
+		RTokens: big.NewInt(10),
+		Amount0: core.FromRawAmount(token0T, big.NewInt(1000)),
+		Amount1: core.FromRawAmount(token1T, big.NewInt(2000)),
+	}
+	params, err = RemoveLiquidityCallParameters(pos, &ProMMRemoveLiquidityOptions{
+		TokenID:             tokenIDT,
+		LiquidityPercentage: core.NewPercent(big.NewInt(1), big.NewInt(1)),
+		SlippageTolerance:   slippageToleranceT,
+		Deadline:            deadlineT,
+		BurnToken:           true,
+		Permit: &NFTPermitOptions{
+			V:        27,
+			R:        common.HexToHash("0x01"),
+			S:        common.HexToHash("0x02"),
+			Deadline: deadlineT,
+			Spender:  senderT,
+		},
+		Fees:           fees,
+		CollectOptions: collectOpts,
+	})
+	assert.NoError(t, err)
+	names, args = decodeBasePositionManagerCalls(t, params.Calldata)
+	assert.Equal(t, []string{"permit", "removeLiquidity", "burnRTokens", "transferAllTokens", "transferAllTokens", "burn"}, names)
+	assert.Equal(t, senderT, args[0][0])
+	assert.Equal(t, uint8(27), args[0][3])
+	removeParams = args[1][0].(struct {
+		TokenId    *big.Int `json:"tokenId"`
+		Liquidity  *big.Int `json:"liquidity"`
+		Amount0Min *big.Int `json:"amount0Min"`
+		Amount1Min *big.Int `json:"amount1Min"`
+		Deadline   *big.Int `json:"deadline"`
+	})
+	burnParams := args[2][0].(struct {
+		TokenId    *big.Int `json:"tokenId"`
+		Amount0Min *big.Int `json:"amount0Min"`
+		Amount1Min *big.Int `json:"amount1Min"`
+		Deadline   *big.Int `json:"deadline"`
+	})
+	assert.Equal(t, big.NewInt(990), burnParams.Amount0Min)
+	assert.Equal(t, big.NewInt(1980), burnParams.Amount1Min)
+	assert.Equal(t, new(big.Int).Add(removeParams.Amount0Min, burnParams.Amount0Min), args[3][1])
+	assert.Equal(t, new(big.Int).Add(removeParams.Amount1Min, burnParams.Amount1Min), args[4][1])
+	assert.Equal(t, []interface{}{tokenIDT}, args[5])
+
+	// unwraps ether
+	pos, err = entities.NewPosition(pool1wethT, big.NewInt(100), -tickSpacing, tickSpacing)
+	assert.NoError(t, err)
+	owed0, owed1 := core.FromRawAmount(token1T, big.NewInt(0)), core.FromRawAmount(core.EtherOnChain(1), big.NewInt(0))
+	if !pool1wethT.Token0.Equal(token1T) {
+		owed0, owed1 = owed1, owed0
+	}
+	params, err = RemoveLiquidityCallParameters(pos, &ProMMRemoveLiquidityOptions{
+		TokenID:             tokenIDT,
+		LiquidityPercentage: core.NewPercent(big.NewInt(1), big.NewInt(1)),
+		SlippageTolerance:   slippageToleranceT,
+		Deadline:            deadlineT,
+		CollectOptions: &ProMMCollectOptions{
+			ExpectedCurrencyOwed0: owed0,
+			ExpectedCurrencyOwed1: owed1,
+			Recipient:             recipientT,
+		},
+	})
+	assert.NoError(t, err)
+	names, _ = decodeBasePositionManagerCalls(t, params.Calldata)
+	assert.Contains(t, names, "unwrapWeth")
+	assert.Contains(t, names, "transferAllTokens")
+}
+
+func TestBurnRTokensCallParameters(t *testing.T) {
+	params, err := BurnRTokensCallParameters(&BurnRTokensOptions{
+		TokenID: tokenIDT,
+		Fees: &entities.PositionFees{
+			RTokens: big.NewInt(10),
+			Amount0: core.FromRawAmount(token0T, big.NewInt(100)),
+			Amount1: core.FromRawAmount(token1T, big.NewInt(200)),
+		},
+		SlippageTolerance: slippageToleranceT,
+		Deadline:          deadlineT,
+		CollectOptions: &ProMMCollectOptions{
+			ExpectedCurrencyOwed0: core.FromRawAmount(token0T, big.NewInt(5)),
+			ExpectedCurrencyOwed1: core.FromRawAmount(token1T, big.NewInt(0)),
+			Recipient:             recipientT,
+		},
+	})
+	assert.NoError(t, err)
+	names, args := decodeBasePositionManagerCalls(t, params.Calldata)
+	assert.Equal(t, []string{"burnRTokens", "transferAllTokens", "transferAllTokens"}, names)
+	assert.Equal(t, []interface{}{token0T.Address, big.NewInt(104), recipientT}, args[1])
+	assert.Equal(t, []interface{}{token1T.Address, big.NewInt(198), recipientT}, args[2])
+	assert.Equal(t, "0x00", utils.ToHex(params.Value))
+}
+
+func TestSyncFeeGrowthCallParameters(t *testing.T) {
+	params, err := SyncFeeGrowthCallParameters(tokenIDT)
+	assert.NoError(t, err)
+	names, args := decodeBasePositionManagerCalls(t, params.Calldata)
+	assert.Equal(t, []string{"syncFeeGrowth"}, names)
+	assert.Equal(t, []interface{}{tokenIDT}, args[0])
+}
+
+func TestTransferAllTokensCallParameters(t *testing.T) {
+	params, err := TransferAllTokensCallParameters(&ProMMCollectOptions{
+		ExpectedCurrencyOwed0: core.FromRawAmount(token0T, big.NewInt(1)),
+		ExpectedCurrencyOwed1: core.FromRawAmount(core.EtherOnChain(1), big.NewInt(2)),
+		Recipient:             recipientT,
+	})
+	assert.NoError(t, err)
+	names, args := decodeBasePositionManagerCalls(t, params.Calldata)
+	assert.Equal(t, []string{"transferAllTokens", "unwrapWeth"}, names)
+	assert.Equal(t, []interface{}{token0T.Address, big.NewInt(1), recipientT}, args[0])
+	assert.Equal(t, []interface{}{big.NewInt(2), recipientT}, args[1])
+}
@@ -0,0 +1,275 @@
+package periphery
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+)
+
+var (
+	ErrUnknownMethod = errors.New("unknown method")
+)
+
+type SelfPermitParams struct {
+	Token    common.Address
+	Value    *big.Int
+	Deadline *big.Int
+	V        uint8
+	R        [32]byte
+	S        [32]byte
+}
+
+type SelfPermitAllowedParams struct {
+	Token  common.Address
+	Nonce  *big.Int
+	Expiry *big.Int
+	V      uint8
+	R      [32]byte
+	S      [32]byte
+}
+
+type SweepTokenParams struct {
+	Token         common.Address
+	AmountMinimum *big.Int
+	Recipient     common.Address
+	FeeBips       *big.Int // nil without fee
+	FeeRecipient  common.Address
+}
+
+type UnwrapWETH9Params struct {
+	AmountMinimum *big.Int
+	Recipient     common.Address
+	FeeBips       *big.Int // nil without fee
+	FeeRecipient  common.Address
+}
+
+type UnwrapWethParams struct {
+	MinAmount *big.Int
+	Recipient common.Address
+}
+
+type TransferAllTokensParams struct {
+	Token     common.Address
+	MinAmount *big.Int
+	Recipient common.Address
+}
+
+type TokenIDParams struct {
+	TokenId *big.Int
+}
+
+type CreatePoolParams struct {
+	Token0       common.Address
+	Token1       common.Address
+	Fee          *big.Int
+	SqrtPriceX96 *big.Int
+}
+
+type PositionPermitParams struct {
+	Spender  common.Address
+	TokenId  *big.Int
+	Deadline *big.Int
+	V        uint8
+	R        [32]byte
+	S        [32]byte
+}
+
+type SafeTransferFromParams struct {
+	From    common.Address
+	To      common.Address
+	TokenId *big.Int
+	Data    []byte // nil for the overload without data
+}
+
+type ClaimRewardParams struct {
+	RewardToken     common.Address
+	To              common.Address
+	AmountRequested *big.Int
+}
+
+type StakeTokenParams struct {
+	Key     IncentiveKeyParams
+	TokenId *big.Int
+}
+
+type WithdrawTokenParams struct {
+	TokenId *big.Int
+	To      common.Address
+	Data    []byte
+}
+
+// SwapPath is the pools a swap goes through, in the order of the swap
+type SwapPath struct {
+	Tokens []common.Address      // The tokens of the swap, from the input token to the output token
+	Fees   []constants.FeeAmount // The fee of the pool between each pair of consecutive tokens
+}
+
+// Operation is a call decoded from the calldata of a periphery contract
+type Operation struct {
+	Method string      // The name of the called method
+	Params interface{} // The arguments, a pointer to the params struct of the method, e.g. *ExactInputParams, nil for methods without arguments, or a map of the arguments by name for methods without a params struct
+	Path   *SwapPath   // The path of swap methods
+}
+
+// the params structs of the methods by name, methods with the same name take compatible arguments in every contract
+var operationParams = map[string]reflect.Type{
+	// swap router
+	"exactInput":                   reflect.TypeOf(ExactInputParams{}),
+	"exactInputSingle":             reflect.TypeOf(ExactInputSingleParams{}),
+	"exactOutput":                  reflect.TypeOf(ExactOutputParams{}),
+	"exactOutputSingle":            reflect.TypeOf(ExactOutputSingleParams{}),
+	"selfPermit":                   reflect.TypeOf(SelfPermitParams{}),
+	"selfPermitIfNecessary":        reflect.TypeOf(SelfPermitParams{}),
+	"selfPermitAllowed":            reflect.TypeOf(SelfPermitAllowedParams{}),
+	"selfPermitAllowedIfNecessary": reflect.TypeOf(SelfPermitAllowedParams{}),
+	"sweepToken":                   reflect.TypeOf(SweepTokenParams{}),
+	"sweepTokenWithFee":            reflect.TypeOf(SweepTokenParams{}),
+	"unwrapWETH9":                  reflect.TypeOf(UnwrapWETH9Params{}),
+	"unwrapWETH9WithFee":           reflect.TypeOf(UnwrapWETH9Params{}),
+
+	// position managers
+	"createAndInitializePoolIfNecessary": reflect.TypeOf(CreatePoolParams{}),
+	"increaseLiquidity":                  reflect.TypeOf(IncreaseLiquidityParams{}),
+	"decreaseLiquidity":                  reflect.TypeOf(DecreaseLiquidityParams{}),
+	"collect":                            reflect.TypeOf(CollectParams{}),
+	"burn":                               reflect.TypeOf(TokenIDParams{}),
+	"permit":                             reflect.TypeOf(PositionPermitParams{}),
+	"safeTransferFrom":                   reflect.TypeOf(SafeTransferFromParams{}),
+	"addLiquidity":                       reflect.TypeOf(ProMMIncreaseLiquidityParams{}),
+	"removeLiquidity":                    reflect.TypeOf(ProMMRemoveLiquidityParams{}),
+	"burnRTokens":                        reflect.TypeOf(ProMMBurnRTokenParams{}),
+	"syncFeeGrowth":                      reflect.TypeOf(TokenIDParams{}),
+	"transferAllTokens":                  reflect.TypeOf(TransferAllTokensParams{}),
+	"unwrapWeth":                         reflect.TypeOf(UnwrapWethParams{}),
+
+	// staker
+	"claimReward":   reflect.TypeOf(ClaimRewardParams{}),
+	"stakeToken":    reflect.TypeOf(StakeTokenParams{}),
+	"unstakeToken":  reflect.TypeOf(StakeTokenParams{}),
+	"withdrawToken": reflect.TypeOf(WithdrawTokenParams{}),
+}
+
+// decodableMethod is a method of a periphery contract and the params struct of its arguments
+type decodableMethod struct {
+	abi.Method
+	params reflect.Type // nil if the method has no params struct
+}
+
+// the methods of the periphery contracts by selector
+var decodableMethods = func() map[[4]byte]decodableMethod {
+	methods := make(map[[4]byte]decodableMethod)
+	for _, contract := range []struct {
+		abi    abi.ABI
+		params map[string]reflect.Type // the params structs of the methods specific to the contract
+	}{
+		{abi: GetABI(swapRouterABI)},
+		{abi: getNonFungiblePositionManagerABI(), params: map[string]reflect.Type{"mint": reflect.TypeOf(MintParams{})}},
+		{abi: getBasePositionManagerABI(), params: map[string]reflect.Type{"mint": reflect.TypeOf(ProMMMintParams{})}},
+		{abi: GetABI(stakerABI)},
+		{abi: getSelfPermitABI()},
+		{abi: GetABI(paymentsABI)},
+	} {
+		for _, method := range contract.abi.Methods {
+			var selector [4]byte
+			copy(selector[:], method.ID)
+			if _, ok := methods[selector]; ok {
+				continue
+			}
+			params, ok := contract.params[method.RawName]
+			if !ok {
+				params = operationParams[method.RawName]
+			}
+			methods[selector] = decodableMethod{Method: method, params: params}
+		}
+	}
+	return methods
+}()
+
+/**
+ * Decodes the calldata of a call to the swap router, a position manager, the staker or the self permit methods.
+ * Multicalls are flattened into the operations of their calls
+ * @param calldata The calldata, e.g. MethodParameters.Calldata
+ * @returns The operations of the calldata, in the order they are executed
+ */
+func DecodeCalldata(calldata []byte) ([]*Operation, error) {
+	if len(calldata) < 4 {
+		return nil, ErrUnknownMethod
+	}
+	var selector [4]byte
+	copy(selector[:], calldata)
+	method, ok := decodableMethods[selector]
+	if !ok {
+		return nil, ErrUnknownMethod
+	}
+	values, err := method.Inputs.Unpack(calldata[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	if method.RawName == "multicall" {
+		var operations []*Operation
+		for _, data := range values[0].([][]byte) {
+			calls, err := DecodeCalldata(data)
+			if err != nil {
+				return nil, err
+			}
+			operations = append(operations, calls...)
+		}
+		return operations, nil
+	}
+
+	operation := &Operation{Method: method.RawName}
+	switch {
+	case method.params != nil:
+		params := reflect.New(method.params).Interface()
+		if len(method.Inputs) == 1 && method.Inputs[0].Type.T == abi.TupleTy {
+			// the params struct is the single tuple argument itself
+			operation.Params = abi.ConvertType(values[0], params)
+			break
+		}
+		if err := method.Inputs.Copy(params, values); err != nil {
+			return nil, err
+		}
+		operation.Params = params
+	case len(method.Inputs) > 0:
+		params := make(map[string]interface{})
+		if err := method.Inputs.UnpackIntoMap(params, calldata[4:]); err != nil {
+			return nil, err
+		}
+		operation.Params = params
+	}
+
+	switch params := operation.Params.(type) {
+	case *ExactInputParams:
+		operation.Path, err = decodeSwapPath(params.Path, false)
+	case *ExactOutputParams:
+		operation.Path, err = decodeSwapPath(params.Path, true)
+	case *ExactInputSingleParams:
+		operation.Path = &SwapPath{
+			Tokens: []common.Address{params.TokenIn, params.TokenOut},
+			Fees:   []constants.FeeAmount{constants.FeeAmount(params.Fee.Uint64())},
+		}
+	case *ExactOutputSingleParams:
+		operation.Path = &SwapPath{
+			Tokens: []common.Address{params.TokenIn, params.TokenOut},
+			Fees:   []constants.FeeAmount{constants.FeeAmount(params.Fee.Uint64())},
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*Operation{operation}, nil
+}
+
+func decodeSwapPath(path []byte, exactOutput bool) (*SwapPath, error) {
+	tokens, fees, err := DecodePath(path, exactOutput)
+	if err != nil {
+		return nil, err
+	}
+	return &SwapPath{Tokens: tokens, Fees: fees}, nil
+}
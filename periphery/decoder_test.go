@@ -0,0 +1,201 @@
+package periphery
+
+import (
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/entities"
+)
+
+func TestDecodeCalldata_SwapRouter(t *testing.T) {
+	routerABI := GetABI(swapRouterABI)
+	path, err := EncodeRouteToPath(route_0_1_2, false)
+	assert.NoError(t, err)
+	exactInput, err := routerABI.Pack("exactInput", &ExactInputParams{
+		Path:             path,
+		Recipient:        recipientT,
+		Deadline:         deadlineT,
+		AmountIn:         big.NewInt(100),
+		AmountOutMinimum: big.NewInt(90),
+	})
+	assert.NoError(t, err)
+	permit, err := EncodePermit(token0, &PermitOptions{StandardPermitArguments: &StandardPermitArguments{
+		V:        27,
+		R:        [32]byte{1},
+		S:        [32]byte{2},
+		Amount:   big.NewInt(100),
+		Deadline: deadlineT,
+	}})
+	assert.NoError(t, err)
+	unwrap, err := EncodeUnwrapWETH9(big.NewInt(90), recipientT, nil)
+	assert.NoError(t, err)
+	sweep, err := EncodeSweepToken(token1, big.NewInt(1), recipientT, &FeeOptions{
+		Fee:       core.NewPercent(big.NewInt(5), big.NewInt(1000)),
+		Recipient: senderT,
+	})
+	assert.NoError(t, err)
+	calldata, err := EncodeMulticall([][]byte{permit, exactInput, unwrap, sweep, EncodeRefundETH()})
+	assert.NoError(t, err)
+
+	operations, err := DecodeCalldata(calldata)
+	assert.NoError(t, err)
+	assert.Len(t, operations, 5)
+
+	assert.Equal(t, "selfPermit", operations[0].Method)
+	assert.Equal(t, &SelfPermitParams{
+		Token:    token0.Address,
+		Value:    big.NewInt(100),
+		Deadline: deadlineT,
+		V:        27,
+		R:        [32]byte{1},
+		S:        [32]byte{2},
+	}, operations[0].Params)
+
+	assert.Equal(t, "exactInput", operations[1].Method)
+	assert.Equal(t, &ExactInputParams{
+		Path:             path,
+		Recipient:        recipientT,
+		Deadline:         deadlineT,
+		AmountIn:         big.NewInt(100),
+		AmountOutMinimum: big.NewInt(90),
+	}, operations[1].Params)
+	assert.Equal(t, &SwapPath{
+		Tokens: []common.Address{token0.Address, token1.Address, token2.Address},
+		Fees:   []constants.FeeAmount{route_0_1_2.Pools[0].Fee, route_0_1_2.Pools[1].Fee},
+	}, operations[1].Path)
+
+	assert.Equal(t, "unwrapWETH9", operations[2].Method)
+	assert.Equal(t, &UnwrapWETH9Params{AmountMinimum: big.NewInt(90), Recipient: recipientT}, operations[2].Params)
+	assert.Equal(t, "sweepTokenWithFee", operations[3].Method)
+	assert.Equal(t, &SweepTokenParams{
+		Token:         token1.Address,
+		AmountMinimum: big.NewInt(1),
+		Recipient:     recipientT,
+		FeeBips:       big.NewInt(50),
+		FeeRecipient:  senderT,
+	}, operations[3].Params)
+	assert.Equal(t, &Operation{Method: "refundETH"}, operations[4])
+
+	// the path of exact output swaps is decoded in the order of the swap
+	path, err = EncodeRouteToPath(route_0_1_2, true)
+	assert.NoError(t, err)
+	calldata, err = routerABI.Pack("exactOutput", &ExactOutputParams{
+		Path:            path,
+		Recipient:       recipientT,
+		Deadline:        deadlineT,
+		AmountOut:       big.NewInt(100),
+		AmountInMaximum: big.NewInt(110),
+	})
+	assert.NoError(t, err)
+	operations, err = DecodeCalldata(calldata)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{token0.Address, token1.Address, token2.Address}, operations[0].Path.Tokens)
+
+	_, err = DecodeCalldata([]byte{1, 2, 3, 4})
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+	_, err = DecodeCalldata(append(exactInput[:4:4], make([]byte, 8)...))
+	assert.Error(t, err)
+}
+
+func TestDecodeCalldata_PositionManagers(t *testing.T) {
+	tickSpacing := constants.TickSpacings[feeT]
+	pos, err := entities.NewPosition(pool01T, big.NewInt(1), -tickSpacing, tickSpacing)
+	assert.NoError(t, err)
+	mintOpts := &AddLiquidityOptions{
+		CommonAddLiquidityOptions: &CommonAddLiquidityOptions{
+			SlippageTolerance: slippageToleranceT,
+			Deadline:          deadlineT,
+		},
+		MintSpecificOptions: &MintSpecificOptions{
+			Recipient:  recipientT,
+			CreatePool: true,
+		},
+	}
+
+	// uniswap style position manager
+	params, err := AddCallParameters(pos, mintOpts)
+	assert.NoError(t, err)
+	operations, err := DecodeCalldata(params.Calldata)
+	assert.NoError(t, err)
+	assert.Len(t, operations, 2)
+	assert.Equal(t, "createAndInitializePoolIfNecessary", operations[0].Method)
+	assert.Equal(t, token0T.Address, operations[0].Params.(*CreatePoolParams).Token0)
+	assert.Equal(t, "mint", operations[1].Method)
+	mintParams := operations[1].Params.(*MintParams)
+	assert.Equal(t, big.NewInt(int64(-tickSpacing)), mintParams.TickLower)
+	assert.Equal(t, recipientT, mintParams.Recipient)
+
+	// ProMM position manager
+	params, err = MintCallParameters(pos, &MintOptions{
+		CommonAddLiquidityOptions: mintOpts.CommonAddLiquidityOptions,
+		MintSpecificOptions:       &MintSpecificOptions{Recipient: recipientT},
+	})
+	assert.NoError(t, err)
+	operations, err = DecodeCalldata(params.Calldata)
+	assert.NoError(t, err)
+	assert.Equal(t, "mint", operations[0].Method)
+	proMMMintParams := operations[0].Params.(*ProMMMintParams)
+	assert.Equal(t, [2]*big.Int{big.NewInt(-887272), big.NewInt(-887272)}, proMMMintParams.TicksPrevious)
+
+	params, err = RemoveLiquidityCallParameters(pos, &ProMMRemoveLiquidityOptions{
+		TokenID:             tokenIDT,
+		LiquidityPercentage: core.NewPercent(big.NewInt(1), big.NewInt(1)),
+		SlippageTolerance:   slippageToleranceT,
+		Deadline:            deadlineT,
+		BurnToken:           true,
+		Fees: &entities.PositionFees{
+			Amount0: core.FromRawAmount(token0T, big.NewInt(0)),
+			Amount1: core.FromRawAmount(token1T, big.NewInt(0)),
+		},
+		CollectOptions: &ProMMCollectOptions{
+			ExpectedCurrencyOwed0: core.FromRawAmount(token0T, big.NewInt(0)),
+			ExpectedCurrencyOwed1: core.FromRawAmount(token1T, big.NewInt(0)),
+			Recipient:             recipientT,
+		},
+	})
+	assert.NoError(t, err)
+	operations, err = DecodeCalldata(params.Calldata)
+	assert.NoError(t, err)
+	var methods []string
+	for _, operation := range operations {
+		methods = append(methods, operation.Method)
+	}
+	assert.Equal(t, []string{"removeLiquidity", "burnRTokens", "transferAllTokens", "transferAllTokens", "burn"}, methods)
+	assert.Equal(t, &TokenIDParams{TokenId: tokenIDT}, operations[4].Params)
+	transferParams := operations[3].Params.(*TransferAllTokensParams)
+	assert.Equal(t, token1T.Address, transferParams.Token)
+	assert.Equal(t, 0, transferParams.MinAmount.Sign())
+	assert.Equal(t, recipientT, transferParams.Recipient)
+}
+
+func TestDecodeCalldata_Staker(t *testing.T) {
+	stakerABI := GetABI(stakerABI)
+	key := IncentiveKeyParams{
+		RewardToken: token0.Address,
+		Pool:        common.HexToAddress("0x0000000000000000000000000000000000000007"),
+		StartTime:   big.NewInt(100),
+		EndTime:     big.NewInt(200),
+		Refundee:    recipientT,
+	}
+	stake, err := stakerABI.Pack("stakeToken", key, tokenIDT)
+	assert.NoError(t, err)
+	claim, err := stakerABI.Pack("claimReward", token0.Address, recipientT, big.NewInt(10))
+	assert.NoError(t, err)
+	transfer, err := stakerABI.Pack("transferDeposit", tokenIDT, recipientT)
+	assert.NoError(t, err)
+	calldata, err := EncodeMulticall([][]byte{stake, claim, transfer})
+	assert.NoError(t, err)
+
+	operations, err := DecodeCalldata(calldata)
+	assert.NoError(t, err)
+	assert.Equal(t, &StakeTokenParams{Key: key, TokenId: tokenIDT}, operations[0].Params)
+	assert.Equal(t, &ClaimRewardParams{RewardToken: token0.Address, To: recipientT, AmountRequested: big.NewInt(10)}, operations[1].Params)
+	// methods without a params struct are decoded by argument name
+	assert.Equal(t, "transferDeposit", operations[2].Method)
+	assert.Equal(t, map[string]interface{}{"tokenId": tokenIDT, "to": recipientT}, operations[2].Params)
+}
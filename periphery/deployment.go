@@ -0,0 +1,71 @@
+package periphery
+
+import (
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+)
+
+// wrappedToken returns the token of a currency, the native currency is wrapped into the WETH of the deployment of its
+// chain, ErrUnknownChain is returned for the native currency of chains without a registered deployment
+func wrappedToken(currency core.Currency) (*core.Token, error) {
+	if !currency.IsNative() {
+		return currency.Wrapped(), nil
+	}
+	deployment, err := constants.GetDeployment(currency.ChainId())
+	if err != nil {
+		return nil, err
+	}
+	if deployment.WETH == nil {
+		return currency.Wrapped(), nil
+	}
+	return deployment.WETH, nil
+}
+
+/**
+ * Returns the address of a contract of the deployment of a chain
+ * @param chainID The chain ID
+ * @param contract Selects the address of the contract in the deployment
+ * @returns The address, the zero address if the chain or the contract is not known, so that the call parameters leave
+ * the target to the caller
+ */
+func contractAddress(chainID uint, contract func(*constants.Deployment) common.Address) common.Address {
+	deployment, err := constants.GetDeployment(chainID)
+	if err != nil {
+		return common.Address{}
+	}
+	return contract(deployment)
+}
+
+func routerOf(deployment *constants.Deployment) common.Address {
+	return deployment.Router
+}
+
+func positionManagerOf(deployment *constants.Deployment) common.Address {
+	return deployment.PositionManager
+}
+
+func nonfungiblePositionManagerOf(deployment *constants.Deployment) common.Address {
+	return deployment.NonfungiblePositionManager
+}
+
+func quoterOf(deployment *constants.Deployment) common.Address {
+	return deployment.Quoter
+}
+
+func quoterV2Of(deployment *constants.Deployment) common.Address {
+	return deployment.QuoterV2
+}
+
+func stakerOf(deployment *constants.Deployment) common.Address {
+	return deployment.Staker
+}
+
+func tickReaderOf(deployment *constants.Deployment) common.Address {
+	return deployment.TickReader
+}
+
+func multicallOf(deployment *constants.Deployment) common.Address {
+	return deployment.Multicall
+}
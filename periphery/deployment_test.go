@@ -0,0 +1,89 @@
+package periphery
+
+import (
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+)
+
+func TestWrappedToken(t *testing.T) {
+	wrapped, err := wrappedToken(token0)
+	assert.NoError(t, err)
+	assert.Equal(t, token0, wrapped)
+
+	wrapped, err = wrappedToken(ether)
+	assert.NoError(t, err)
+	assert.Equal(t, weth, wrapped)
+
+	wrapped, err = wrappedToken(core.EtherOnChain(137))
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress("0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270"), wrapped.Address)
+
+	_, err = wrappedToken(core.EtherOnChain(9999))
+	assert.ErrorIs(t, err, constants.ErrUnknownChain)
+
+	wrappedNative := core.NewToken(9998, common.HexToAddress("0x0000000000000000000000000000000000000005"), 18, "WN", "Wrapped Native")
+	constants.Deployments.Register(9998, &constants.Deployment{WETH: wrappedNative})
+	wrapped, err = wrappedToken(core.EtherOnChain(9998))
+	assert.NoError(t, err)
+	assert.Equal(t, wrappedNative, wrapped)
+}
+
+func TestMethodParametersTarget(t *testing.T) {
+	// the periphery contracts of the built-in deployments are not known, the target is left to the caller
+	params, err := CreateCallParameters(pool_0_1_medium)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Address{}, params.To)
+	assert.Equal(t, uint(1), params.ChainID)
+
+	params, err = SyncFeeGrowthCallParametersWithChainID(big.NewInt(1), 9999)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Address{}, params.To)
+	assert.Equal(t, uint(9999), params.ChainID)
+
+	shipped, err := constants.GetDeployment(1)
+	assert.NoError(t, err)
+	defer constants.Deployments.Register(1, shipped)
+	deployment := *shipped
+	deployment.PositionManager = common.HexToAddress("0x0000000000000000000000000000000000000012")
+	deployment.NonfungiblePositionManager = common.HexToAddress("0x0000000000000000000000000000000000000017")
+	deployment.Quoter = common.HexToAddress("0x0000000000000000000000000000000000000013")
+	deployment.QuoterV2 = common.HexToAddress("0x0000000000000000000000000000000000000014")
+	constants.Deployments.Register(1, &deployment)
+
+	params, err = CreateCallParameters(pool_0_1_medium)
+	assert.NoError(t, err)
+	assert.Equal(t, deployment.NonfungiblePositionManager, params.To)
+	assert.Equal(t, uint(1), params.ChainID)
+
+	params, err = QuoteCallParameters(route_0_1_2, core.FromRawAmount(token0, big.NewInt(100)), core.ExactInput, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, deployment.Quoter, params.To)
+	assert.Equal(t, uint(1), params.ChainID)
+
+	params, err = QuoteV2CallParameters(route_0_1_2, core.FromRawAmount(token0, big.NewInt(100)), core.ExactInput, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, deployment.QuoterV2, params.To)
+	assert.Equal(t, uint(1), params.ChainID)
+
+	params, err = SyncFeeGrowthCallParametersWithChainID(big.NewInt(1), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, deployment.PositionManager, params.To)
+	assert.Equal(t, uint(1), params.ChainID)
+
+	params, err = SafeTransferFromParametersWithChainID(&SafeTransferOptions{TokenID: big.NewInt(1)}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, deployment.NonfungiblePositionManager, params.To)
+	assert.Equal(t, uint(1), params.ChainID)
+
+	// the encoders that cannot tell the chain leave the target to the caller
+	params, err = SafeTransferFromParameters(&SafeTransferOptions{TokenID: big.NewInt(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, common.Address{}, params.To)
+	assert.Equal(t, uint(0), params.ChainID)
+}
@@ -2,17 +2,17 @@ package periphery
 
 import (
 	_ "embed"
-	"encoding/json"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
+
+	"github.com/KyberNetwork/promm-sdk-go/abis"
 )
 
 //go:embed contracts/interfaces/IMulticall.sol/IMulticall.json
 var multicallABI []byte
 
-type WrappedABI struct {
-	ABI abi.ABI `json:"abi"`
-}
+// WrappedABI is kept for compatibility, see abis.WrappedABI
+type WrappedABI = abis.WrappedABI
 
 func EncodeMulticall(calldatas [][]byte) ([]byte, error) {
 	if len(calldatas) == 1 {
@@ -27,11 +27,7 @@ func EncodeMulticall(calldatas [][]byte) ([]byte, error) {
 	return b, nil
 }
 
+// GetABI is kept for compatibility, see abis.GetABI
 func GetABI(abi []byte) abi.ABI {
-	var wabi WrappedABI
-	err := json.Unmarshal(abi, &wabi)
-	if err != nil {
-		panic(err)
-	}
-	return wabi.ABI
+	return abis.GetABI(abi)
 }
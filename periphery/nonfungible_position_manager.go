@@ -85,12 +85,13 @@ type CollectOptions struct {
 	Recipient             common.Address // The account that should receive the tokens
 }
 
+// The permit of a position, see SignNFTPermit
 type NFTPermitOptions struct {
-	V        uint
-	R        string
-	S        string
+	V        uint8
+	R        [32]byte
+	S        [32]byte
 	Deadline *big.Int
-	Spender  string
+	Spender  common.Address
 }
 
 // Options for producing the calldata to exit a position
@@ -152,9 +153,12 @@ func CreateCallParameters(pool *entities.Pool) (*utils.MethodParameters, error)
 	if err != nil {
 		return nil, err
 	}
+	to := contractAddress(pool.ChainID(), nonfungiblePositionManagerOf)
 	return &utils.MethodParameters{
 		Calldata: calldata,
 		Value:    constants.Zero,
+		To:       to,
+		ChainID:  pool.ChainID(),
 	}, nil
 }
 
@@ -243,7 +247,10 @@ func AddCallParameters(position *entities.Position, opts *AddLiquidityOptions) (
 
 	value := constants.Zero
 	if opts.UseNative != nil {
-		wrapped := opts.UseNative.Wrapped()
+		wrapped, err := wrappedToken(opts.UseNative)
+		if err != nil {
+			return nil, err
+		}
 		if !position.Pool.Token0.Equal(wrapped) && !position.Pool.Token1.Equal(wrapped) {
 			return nil, ErrNoWETH
 		}
@@ -265,9 +272,12 @@ func AddCallParameters(position *entities.Position, opts *AddLiquidityOptions) (
 		return nil, err
 	}
 
+	to := contractAddress(position.Pool.ChainID(), nonfungiblePositionManagerOf)
 	return &utils.MethodParameters{
 		Calldata: datas,
 		Value:    value,
+		To:       to,
+		ChainID:  position.Pool.ChainID(),
 	}, nil
 }
 
@@ -301,11 +311,17 @@ func encodeCollect(opts *CollectOptions) ([][]byte, error) {
 		)
 		if opts.ExpectedCurrencyOwed0.Currency.IsNative() {
 			ethAmount = opts.ExpectedCurrencyOwed0.Quotient()
-			token = opts.ExpectedTokenOwed1.Wrapped()
+			token, err = wrappedToken(opts.ExpectedTokenOwed1)
+			if err != nil {
+				return nil, err
+			}
 			tokenAmount = opts.ExpectedCurrencyOwed1.Quotient()
 		} else {
 			ethAmount = opts.ExpectedCurrencyOwed1.Quotient()
-			token = opts.ExpectedTokenOwed0.Wrapped()
+			token, err = wrappedToken(opts.ExpectedTokenOwed0)
+			if err != nil {
+				return nil, err
+			}
 			tokenAmount = opts.ExpectedCurrencyOwed0.Quotient()
 		}
 
@@ -333,9 +349,12 @@ func CollectCallParameters(opts *CollectOptions) (*utils.MethodParameters, error
 	if err != nil {
 		return nil, err
 	}
+	to := contractAddress(opts.ExpectedCurrencyOwed0.Currency.ChainId(), nonfungiblePositionManagerOf)
 	return &utils.MethodParameters{
 		Calldata: data,
 		Value:    constants.Zero,
+		To:       to,
+		ChainID:  opts.ExpectedCurrencyOwed0.Currency.ChainId(),
 	}, nil
 }
 
@@ -423,12 +442,20 @@ func RemoveCallParameters(position *entities.Position, opts *RemoveLiquidityOpti
 	if err != nil {
 		return nil, err
 	}
+	to := contractAddress(position.Pool.ChainID(), nonfungiblePositionManagerOf)
 	return &utils.MethodParameters{
 		Calldata: data,
 		Value:    constants.Zero,
+		To:       to,
+		ChainID:  position.Pool.ChainID(),
 	}, nil
 }
 
+/**
+ * Produces the calldata for transferring a position to another account
+ * @param opts The options of the transfer
+ * @returns The call parameters, without a target as the options do not tell the chain of the position
+ */
 func SafeTransferFromParameters(opts *SafeTransferOptions) (*utils.MethodParameters, error) {
 	abi := getNonFungiblePositionManagerABI()
 
@@ -452,3 +479,19 @@ func SafeTransferFromParameters(opts *SafeTransferOptions) (*utils.MethodParamet
 		Value:    constants.Zero,
 	}, nil
 }
+
+/**
+ * Produces the calldata for transferring a position to another account, targeting the position manager of a chain
+ * @param opts The options of the transfer
+ * @param chainID The chain ID of the position manager
+ * @returns The call parameters
+ */
+func SafeTransferFromParametersWithChainID(opts *SafeTransferOptions, chainID uint) (*utils.MethodParameters, error) {
+	params, err := SafeTransferFromParameters(opts)
+	if err != nil {
+		return nil, err
+	}
+	params.To = contractAddress(chainID, nonfungiblePositionManagerOf)
+	params.ChainID = chainID
+	return params, nil
+}
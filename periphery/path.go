@@ -0,0 +1,93 @@
+package periphery
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/entities"
+)
+
+var (
+	ErrInvalidPath  = errors.New("invalid path")
+	ErrPoolNotFound = errors.New("pool not found")
+)
+
+// the size of the fee of a pool in an encoded path
+const pathFeeSize = 3
+
+/**
+ * Decodes a path encoded by EncodeRouteToPath
+ * @param path The encoded path
+ * @param exactOutput Whether the path is encoded in reverse, as for exact output swaps
+ * @returns The tokens of the path from the input token to the output token, and the fee of the pool between each pair
+ * of consecutive tokens
+ */
+func DecodePath(path []byte, exactOutput bool) ([]common.Address, []constants.FeeAmount, error) {
+	if len(path) < common.AddressLength || (len(path)-common.AddressLength)%(pathFeeSize+common.AddressLength) != 0 {
+		return nil, nil, ErrInvalidPath
+	}
+
+	tokens := []common.Address{common.BytesToAddress(path[:common.AddressLength])}
+	var fees []constants.FeeAmount
+	for offset := common.AddressLength; offset < len(path); offset += pathFeeSize + common.AddressLength {
+		fee := path[offset : offset+pathFeeSize]
+		fees = append(fees, constants.FeeAmount(uint64(fee[0])<<16|uint64(fee[1])<<8|uint64(fee[2])))
+		tokens = append(tokens, common.BytesToAddress(path[offset+pathFeeSize:offset+pathFeeSize+common.AddressLength]))
+	}
+
+	if exactOutput {
+		reverse(tokens)
+		reverse(fees)
+	}
+	return tokens, fees, nil
+}
+
+/**
+ * Decodes a path encoded by EncodeRouteToPath into a route through the given pools
+ * @param path The encoded path
+ * @param exactOutput Whether the path is encoded in reverse, as for exact output swaps
+ * @param pools The pools the route can go through, each hop of the path must match the tokens and the fee of one of them
+ * @returns The route, from the input token to the output token of the path
+ */
+func RouteFromPath(path []byte, exactOutput bool, pools []*entities.Pool) (*entities.Route, error) {
+	tokens, fees, err := DecodePath(path, exactOutput)
+	if err != nil {
+		return nil, err
+	}
+	if len(fees) == 0 {
+		return nil, ErrInvalidPath
+	}
+
+	routePools := make([]*entities.Pool, 0, len(fees))
+	for i, fee := range fees {
+		pool := findPool(pools, tokens[i], tokens[i+1], fee)
+		if pool == nil {
+			return nil, ErrPoolNotFound
+		}
+		routePools = append(routePools, pool)
+	}
+
+	input, output := routePools[0].Token0, routePools[len(routePools)-1].Token0
+	if input.Address != tokens[0] {
+		input = routePools[0].Token1
+	}
+	if output.Address != tokens[len(tokens)-1] {
+		output = routePools[len(routePools)-1].Token1
+	}
+	return entities.NewRoute(routePools, input, output)
+}
+
+// findPool returns the pool of a pair of tokens and a fee, nil if there is none
+func findPool(pools []*entities.Pool, tokenA, tokenB common.Address, fee constants.FeeAmount) *entities.Pool {
+	for _, pool := range pools {
+		if pool.Fee != fee {
+			continue
+		}
+		if (pool.Token0.Address == tokenA && pool.Token1.Address == tokenB) || (pool.Token0.Address == tokenB && pool.Token1.Address == tokenA) {
+			return pool
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package periphery
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/entities"
+)
+
+func TestDecodePath(t *testing.T) {
+	path, err := EncodeRouteToPath(route_0_1_2, false)
+	assert.NoError(t, err)
+	tokens, fees, err := DecodePath(path, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{token0.Address, token1.Address, token2.Address}, tokens)
+	assert.Equal(t, []constants.FeeAmount{constants.Fee004, constants.Fee001}, fees)
+
+	path, err = EncodeRouteToPath(route_0_1_2, true)
+	assert.NoError(t, err)
+	tokens, fees, err = DecodePath(path, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{token0.Address, token1.Address, token2.Address}, tokens)
+	assert.Equal(t, []constants.FeeAmount{constants.Fee004, constants.Fee001}, fees)
+
+	_, _, err = DecodePath(path[:len(path)-1], true)
+	assert.ErrorIs(t, err, ErrInvalidPath)
+	_, _, err = DecodePath(nil, false)
+	assert.ErrorIs(t, err, ErrInvalidPath)
+}
+
+func TestRouteFromPath(t *testing.T) {
+	pools := []*entities.Pool{pool_0_weth, pool_1_2_low, pool_0_1_medium}
+
+	path, err := EncodeRouteToPath(route_0_1_2, false)
+	assert.NoError(t, err)
+	route, err := RouteFromPath(path, false, pools)
+	assert.NoError(t, err)
+	assert.Equal(t, route_0_1_2.Pools, route.Pools)
+	assert.True(t, route.Input.Equal(token0))
+	assert.True(t, route.Output.Equal(token2))
+
+	path, err = EncodeRouteToPath(route_weth_0_1, true)
+	assert.NoError(t, err)
+	route, err = RouteFromPath(path, true, pools)
+	assert.NoError(t, err)
+	assert.Equal(t, route_weth_0_1.Pools, route.Pools)
+	assert.True(t, route.Input.Equal(weth))
+	assert.True(t, route.Output.Equal(token1))
+
+	path, err = EncodeRouteToPath(route_0_1_weth, false)
+	assert.NoError(t, err)
+	_, err = RouteFromPath(path, false, pools)
+	assert.ErrorIs(t, err, ErrPoolNotFound)
+
+	path, err = EncodeRouteToPath(route_0_1, false)
+	assert.NoError(t, err)
+	path[len(path)-common.AddressLength-1]++ // another fee
+	_, err = RouteFromPath(path, false, pools)
+	assert.ErrorIs(t, err, ErrPoolNotFound)
+}
@@ -0,0 +1,248 @@
+package periphery
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+var (
+	eip712DomainTypeHash  = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	permitTypeHash        = crypto.Keccak256Hash([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+	allowedPermitTypeHash = crypto.Keccak256Hash([]byte("Permit(address holder,address spender,uint256 nonce,uint256 expiry,bool allowed)"))
+	nftPermitTypeHash     = crypto.Keccak256Hash([]byte("Permit(address spender,uint256 tokenId,uint256 nonce,uint256 deadline)"))
+)
+
+// DigestSigner signs EIP-712 digests, e.g. with a private key, a hardware wallet or a remote signer
+type DigestSigner interface {
+	/**
+	 * Sign a digest
+	 * @param digest The digest to sign
+	 * @returns The 65 bytes [R || S || V] signature, V is either 0 or 1, or 27 or 28
+	 */
+	SignDigest(digest common.Hash) ([]byte, error)
+}
+
+// PrivateKeySigner is a DigestSigner signing with a private key
+type PrivateKeySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+/**
+ * Constructs a signer of digests with a private key
+ * @param key The private key
+ */
+func NewPrivateKeySigner(key *ecdsa.PrivateKey) *PrivateKeySigner {
+	return &PrivateKeySigner{key: key}
+}
+
+func (s *PrivateKeySigner) SignDigest(digest common.Hash) ([]byte, error) {
+	return crypto.Sign(digest.Bytes(), s.key)
+}
+
+// The EIP-712 domain of the permits of a token
+type PermitDomain struct {
+	Name              string         // The name of the token in its domain, which may differ from its symbol and display name
+	Version           string         // The version of the domain, "1" for most tokens
+	ChainID           uint           // The chain the token is deployed on
+	VerifyingContract common.Address // The address of the token
+}
+
+// Separator returns the DOMAIN_SEPARATOR of the domain
+func (d *PermitDomain) Separator() common.Hash {
+	return crypto.Keccak256Hash(
+		eip712DomainTypeHash.Bytes(),
+		crypto.Keccak256([]byte(d.Name)),
+		crypto.Keccak256([]byte(d.Version)),
+		math.U256Bytes(new(big.Int).SetUint64(uint64(d.ChainID))),
+		common.LeftPadBytes(d.VerifyingContract.Bytes(), 32),
+	)
+}
+
+// typedDataDigest returns the digest signed for a struct of a domain, see EIP-712
+func typedDataDigest(domain *PermitDomain, structHash common.Hash) common.Hash {
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain.Separator().Bytes(), structHash.Bytes())
+}
+
+// splitSignature returns the V, R and S of a [R || S || V] signature, with V either 27 or 28
+func splitSignature(signature []byte) (uint8, [32]byte, [32]byte, error) {
+	var r, s [32]byte
+	if len(signature) != crypto.SignatureLength {
+		return 0, r, s, ErrInvalidSignature
+	}
+	v := signature[crypto.RecoveryIDOffset]
+	if v < 27 {
+		v += 27
+	}
+	if v != 27 && v != 28 {
+		return 0, r, s, ErrInvalidSignature
+	}
+	copy(r[:], signature[:32])
+	copy(s[:], signature[32:64])
+	return v, r, s, nil
+}
+
+// An EIP-2612 permit, allowing the spender to spend an amount of the tokens of the owner
+type StandardPermit struct {
+	Owner    common.Address
+	Spender  common.Address // The router or the position manager for self permits
+	Value    *big.Int       // The amount allowed
+	Nonce    *big.Int       // The current nonce of the owner in the token
+	Deadline *big.Int       // The timestamp until which the permit is valid
+}
+
+/**
+ * Produces the digest the owner of a permit signs
+ * @param domain The domain of the token
+ * @param permit The permit
+ * @returns The digest
+ */
+func StandardPermitDigest(domain *PermitDomain, permit *StandardPermit) common.Hash {
+	return typedDataDigest(domain, crypto.Keccak256Hash(
+		permitTypeHash.Bytes(),
+		common.LeftPadBytes(permit.Owner.Bytes(), 32),
+		common.LeftPadBytes(permit.Spender.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(permit.Value)),
+		math.U256Bytes(new(big.Int).Set(permit.Nonce)),
+		math.U256Bytes(new(big.Int).Set(permit.Deadline)),
+	))
+}
+
+/**
+ * Signs a permit for the self permit of SwapCallParameters or AddCallParameters
+ * @param domain The domain of the token
+ * @param permit The permit, signed by its owner
+ * @param signer The signer of the owner
+ * @returns The permit options
+ */
+func SignStandardPermit(domain *PermitDomain, permit *StandardPermit, signer DigestSigner) (*PermitOptions, error) {
+	signature, err := signer.SignDigest(StandardPermitDigest(domain, permit))
+	if err != nil {
+		return nil, err
+	}
+	v, r, s, err := splitSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	return &PermitOptions{
+		StandardPermitArguments: &StandardPermitArguments{
+			V:        v,
+			R:        r,
+			S:        s,
+			Amount:   permit.Value,
+			Deadline: permit.Deadline,
+		},
+	}, nil
+}
+
+// A DAI-style permit, allowing the spender to spend all the tokens of the holder, or revoking the allowance
+type AllowedPermit struct {
+	Holder  common.Address
+	Spender common.Address // The router or the position manager for self permits
+	Nonce   *big.Int       // The current nonce of the holder in the token
+	Expiry  *big.Int       // The timestamp until which the permit is valid, zero for no expiry
+	Allowed bool           // Whether the spender is allowed or revoked, self permits are always allowed
+}
+
+/**
+ * Produces the digest the holder of a DAI-style permit signs
+ * @param domain The domain of the token
+ * @param permit The permit
+ * @returns The digest
+ */
+func AllowedPermitDigest(domain *PermitDomain, permit *AllowedPermit) common.Hash {
+	allowed := big.NewInt(0)
+	if permit.Allowed {
+		allowed = big.NewInt(1)
+	}
+	return typedDataDigest(domain, crypto.Keccak256Hash(
+		allowedPermitTypeHash.Bytes(),
+		common.LeftPadBytes(permit.Holder.Bytes(), 32),
+		common.LeftPadBytes(permit.Spender.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(permit.Nonce)),
+		math.U256Bytes(new(big.Int).Set(permit.Expiry)),
+		math.U256Bytes(allowed),
+	))
+}
+
+/**
+ * Signs a DAI-style permit for selfPermitAllowed, which only accepts permits that allow the spender
+ * @param domain The domain of the token
+ * @param permit The permit, signed by its holder
+ * @param signer The signer of the holder
+ * @returns The permit arguments
+ */
+func SignAllowedPermit(domain *PermitDomain, permit *AllowedPermit, signer DigestSigner) (*AllowedPermitArguments, error) {
+	signature, err := signer.SignDigest(AllowedPermitDigest(domain, permit))
+	if err != nil {
+		return nil, err
+	}
+	v, r, s, err := splitSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	return &AllowedPermitArguments{
+		V:      v,
+		R:      r,
+		S:      s,
+		Nonce:  permit.Nonce,
+		Expiry: permit.Expiry,
+	}, nil
+}
+
+// An ERC721 permit, allowing the spender to operate a position, e.g. to exit it on behalf of its owner
+type NFTPermit struct {
+	Spender  common.Address
+	TokenID  *big.Int
+	Nonce    *big.Int // The current nonce of the position in the position manager
+	Deadline *big.Int // The timestamp until which the permit is valid
+}
+
+/**
+ * Produces the digest the owner of a position signs to permit the spender
+ * @param domain The domain of the position manager, whose verifying contract is the position manager
+ * @param permit The permit
+ * @returns The digest
+ */
+func NFTPermitDigest(domain *PermitDomain, permit *NFTPermit) common.Hash {
+	return typedDataDigest(domain, crypto.Keccak256Hash(
+		nftPermitTypeHash.Bytes(),
+		common.LeftPadBytes(permit.Spender.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(permit.TokenID)),
+		math.U256Bytes(new(big.Int).Set(permit.Nonce)),
+		math.U256Bytes(new(big.Int).Set(permit.Deadline)),
+	))
+}
+
+/**
+ * Signs a position permit for the Permit of the remove liquidity options
+ * @param domain The domain of the position manager
+ * @param permit The permit, signed by the owner of the position
+ * @param signer The signer of the owner
+ * @returns The permit options
+ */
+func SignNFTPermit(domain *PermitDomain, permit *NFTPermit, signer DigestSigner) (*NFTPermitOptions, error) {
+	signature, err := signer.SignDigest(NFTPermitDigest(domain, permit))
+	if err != nil {
+		return nil, err
+	}
+	v, r, s, err := splitSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	return &NFTPermitOptions{
+		V:        v,
+		R:        r,
+		S:        s,
+		Deadline: permit.Deadline,
+		Spender:  permit.Spender,
+	}, nil
+}
@@ -0,0 +1,328 @@
+package periphery
+
+import (
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/entities"
+)
+
+var permitDomain = &PermitDomain{
+	Name:              "USD Coin",
+	Version:           "2",
+	ChainID:           1,
+	VerifyingContract: common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"),
+}
+
+// typedDataDomain returns the domain of a permit as go-ethereum typed data
+func typedDataDomain(domain *PermitDomain) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              domain.Name,
+		Version:           domain.Version,
+		ChainId:           math.NewHexOrDecimal256(int64(domain.ChainID)),
+		VerifyingContract: domain.VerifyingContract.Hex(),
+	}
+}
+
+var eip712DomainType = []apitypes.Type{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+}
+
+// signerFunc is a DigestSigner calling a function
+type signerFunc func(digest common.Hash) ([]byte, error)
+
+func (f signerFunc) SignDigest(digest common.Hash) ([]byte, error) {
+	return f(digest)
+}
+
+// typedDataHash returns the digest of go-ethereum typed data
+func typedDataHash(t *testing.T, typedData apitypes.TypedData) common.Hash {
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	assert.NoError(t, err)
+	structHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	assert.NoError(t, err)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator, structHash)
+}
+
+func TestStandardPermitDigest(t *testing.T) {
+	permit := &StandardPermit{
+		Owner:    common.HexToAddress("0x0000000000000000000000000000000000000011"),
+		Spender:  common.HexToAddress("0x0000000000000000000000000000000000000012"),
+		Value:    big.NewInt(1_000_000),
+		Nonce:    big.NewInt(3),
+		Deadline: big.NewInt(1_700_000_000),
+	}
+
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": eip712DomainType,
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      typedDataDomain(permitDomain),
+		Message: apitypes.TypedDataMessage{
+			"owner":    permit.Owner.Hex(),
+			"spender":  permit.Spender.Hex(),
+			"value":    permit.Value.String(),
+			"nonce":    permit.Nonce.String(),
+			"deadline": permit.Deadline.String(),
+		},
+	}
+	assert.Equal(t, typedDataHash(t, typedData), StandardPermitDigest(permitDomain, permit))
+}
+
+func TestSignStandardPermit(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	permit := &StandardPermit{
+		Owner:    crypto.PubkeyToAddress(key.PublicKey),
+		Spender:  common.HexToAddress("0x0000000000000000000000000000000000000012"),
+		Value:    big.NewInt(1_000_000),
+		Nonce:    big.NewInt(0),
+		Deadline: big.NewInt(1_700_000_000),
+	}
+
+	options, err := SignStandardPermit(permitDomain, permit, NewPrivateKeySigner(key))
+	assert.NoError(t, err)
+	assert.Nil(t, options.AllowedPermitArguments)
+	arguments := options.StandardPermitArguments
+	assert.Equal(t, permit.Value, arguments.Amount)
+	assert.Equal(t, permit.Deadline, arguments.Deadline)
+	assert.Contains(t, []uint8{27, 28}, arguments.V)
+
+	signature := append(append(arguments.R[:], arguments.S[:]...), arguments.V-27)
+	publicKey, err := crypto.SigToPub(StandardPermitDigest(permitDomain, permit).Bytes(), signature)
+	assert.NoError(t, err)
+	assert.Equal(t, permit.Owner, crypto.PubkeyToAddress(*publicKey))
+
+	// signers returning V as 27 or 28 are supported
+	signed, err := SignStandardPermit(permitDomain, permit, signerFunc(func(digest common.Hash) ([]byte, error) {
+		return append(signature[:64:64], arguments.V), nil
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, options, signed)
+
+	_, err = SignStandardPermit(permitDomain, permit, signerFunc(func(digest common.Hash) ([]byte, error) {
+		return signature[:64], nil
+	}))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	calldata, err := EncodePermit(token0, options)
+	assert.NoError(t, err)
+	operations, err := DecodeCalldata(calldata)
+	assert.NoError(t, err)
+	assert.Equal(t, &SelfPermitParams{
+		Token:    token0.Address,
+		Value:    permit.Value,
+		Deadline: permit.Deadline,
+		V:        arguments.V,
+		R:        arguments.R,
+		S:        arguments.S,
+	}, operations[0].Params)
+}
+
+var daiDomain = &PermitDomain{
+	Name:              "Dai Stablecoin",
+	Version:           "1",
+	ChainID:           1,
+	VerifyingContract: common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F"),
+}
+
+func TestAllowedPermitDigest(t *testing.T) {
+	// the PERMIT_TYPEHASH and DOMAIN_SEPARATOR of DAI on mainnet
+	assert.Equal(t, common.HexToHash("0xea2aa0a1be11a07ed86d755c93467f4f82362b452371d1ba94d1715123511acb"), allowedPermitTypeHash)
+	assert.Equal(t, common.HexToHash("0xdbb8cf42e1ecb028be3f3dbc922e1d878b963f411dc388ced501601c60f7c6f7"), daiDomain.Separator())
+
+	permit := &AllowedPermit{
+		Holder:  common.HexToAddress("0x0000000000000000000000000000000000000011"),
+		Spender: common.HexToAddress("0x0000000000000000000000000000000000000012"),
+		Nonce:   big.NewInt(3),
+		Expiry:  big.NewInt(1_700_000_000),
+		Allowed: true,
+	}
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": eip712DomainType,
+			"Permit": {
+				{Name: "holder", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "expiry", Type: "uint256"},
+				{Name: "allowed", Type: "bool"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      typedDataDomain(daiDomain),
+		Message: apitypes.TypedDataMessage{
+			"holder":  permit.Holder.Hex(),
+			"spender": permit.Spender.Hex(),
+			"nonce":   permit.Nonce.String(),
+			"expiry":  permit.Expiry.String(),
+			"allowed": true,
+		},
+	}
+	assert.Equal(t, typedDataHash(t, typedData), AllowedPermitDigest(daiDomain, permit))
+
+	permit.Allowed = false
+	typedData.Message["allowed"] = false
+	assert.Equal(t, typedDataHash(t, typedData), AllowedPermitDigest(daiDomain, permit))
+}
+
+func TestSignAllowedPermit(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	permit := &AllowedPermit{
+		Holder:  crypto.PubkeyToAddress(key.PublicKey),
+		Spender: common.HexToAddress("0x0000000000000000000000000000000000000012"),
+		Nonce:   big.NewInt(5),
+		Expiry:  big.NewInt(1_700_000_000),
+		Allowed: true,
+	}
+
+	arguments, err := SignAllowedPermit(daiDomain, permit, NewPrivateKeySigner(key))
+	assert.NoError(t, err)
+	assert.Equal(t, permit.Nonce, arguments.Nonce)
+	assert.Equal(t, permit.Expiry, arguments.Expiry)
+
+	signature := append(append(arguments.R[:], arguments.S[:]...), arguments.V-27)
+	publicKey, err := crypto.SigToPub(AllowedPermitDigest(daiDomain, permit).Bytes(), signature)
+	assert.NoError(t, err)
+	assert.Equal(t, permit.Holder, crypto.PubkeyToAddress(*publicKey))
+
+	calldata, err := EncodePermit(token0, &PermitOptions{AllowedPermitArguments: arguments})
+	assert.NoError(t, err)
+	operations, err := DecodeCalldata(calldata)
+	assert.NoError(t, err)
+	assert.Equal(t, &SelfPermitAllowedParams{
+		Token:  token0.Address,
+		Nonce:  permit.Nonce,
+		Expiry: permit.Expiry,
+		V:      arguments.V,
+		R:      arguments.R,
+		S:      arguments.S,
+	}, operations[0].Params)
+}
+
+var positionManagerDomain = &PermitDomain{
+	Name:              "Uniswap V3 Positions NFT-V1",
+	Version:           "1",
+	ChainID:           1,
+	VerifyingContract: common.HexToAddress("0xC36442b4a4522E871399CD717aBDD847Ab11FE88"),
+}
+
+func TestNFTPermitDigest(t *testing.T) {
+	// the PERMIT_TYPEHASH of ERC721Permit
+	assert.Equal(t, common.HexToHash("0x49ecf333e5b8c95c40fdafc95c1ad136e8914a8fb55e9dc8bb01eaa83a2df9ad"), nftPermitTypeHash)
+
+	permit := &NFTPermit{
+		Spender:  common.HexToAddress("0x0000000000000000000000000000000000000012"),
+		TokenID:  big.NewInt(42),
+		Nonce:    big.NewInt(3),
+		Deadline: big.NewInt(1_700_000_000),
+	}
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": eip712DomainType,
+			"Permit": {
+				{Name: "spender", Type: "address"},
+				{Name: "tokenId", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      typedDataDomain(positionManagerDomain),
+		Message: apitypes.TypedDataMessage{
+			"spender":  permit.Spender.Hex(),
+			"tokenId":  permit.TokenID.String(),
+			"nonce":    permit.Nonce.String(),
+			"deadline": permit.Deadline.String(),
+		},
+	}
+	assert.Equal(t, typedDataHash(t, typedData), NFTPermitDigest(positionManagerDomain, permit))
+}
+
+func TestSignNFTPermit(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	permit := &NFTPermit{
+		Spender:  senderT,
+		TokenID:  tokenIDT,
+		Nonce:    big.NewInt(3),
+		Deadline: deadlineT,
+	}
+
+	options, err := SignNFTPermit(positionManagerDomain, permit, NewPrivateKeySigner(key))
+	assert.NoError(t, err)
+	assert.Equal(t, permit.Spender, options.Spender)
+	assert.Equal(t, permit.Deadline, options.Deadline)
+
+	signature := append(append(options.R[:], options.S[:]...), options.V-27)
+	publicKey, err := crypto.SigToPub(NFTPermitDigest(positionManagerDomain, permit).Bytes(), signature)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(*publicKey))
+
+	// the signed permit is accepted by the exits of both position managers
+	expected := &PositionPermitParams{
+		Spender:  permit.Spender,
+		TokenId:  permit.TokenID,
+		Deadline: permit.Deadline,
+		V:        options.V,
+		R:        options.R,
+		S:        options.S,
+	}
+	pos, err := entities.NewPosition(pool01T, big.NewInt(100), -constants.TickSpacings[feeT], constants.TickSpacings[feeT])
+	assert.NoError(t, err)
+	params, err := RemoveCallParameters(pos, &RemoveLiquidityOptions{
+		TokenID:             tokenIDT,
+		LiquidityPercentage: core.NewPercent(big.NewInt(1), big.NewInt(1)),
+		SlippageTolerance:   slippageToleranceT,
+		Deadline:            deadlineT,
+		Permit:              options,
+		CollectOptions: &CollectOptions{
+			ExpectedCurrencyOwed0: core.FromRawAmount(token0T, big.NewInt(0)),
+			ExpectedCurrencyOwed1: core.FromRawAmount(token1T, big.NewInt(0)),
+			Recipient:             recipientT,
+		},
+	})
+	assert.NoError(t, err)
+	operations, err := DecodeCalldata(params.Calldata)
+	assert.NoError(t, err)
+	assert.Equal(t, "permit", operations[0].Method)
+	assert.Equal(t, expected, operations[0].Params)
+
+	params, err = RemoveLiquidityCallParameters(pos, &ProMMRemoveLiquidityOptions{
+		TokenID:             tokenIDT,
+		LiquidityPercentage: core.NewPercent(big.NewInt(1), big.NewInt(1)),
+		SlippageTolerance:   slippageToleranceT,
+		Deadline:            deadlineT,
+		Permit:              options,
+		CollectOptions: &ProMMCollectOptions{
+			ExpectedCurrencyOwed0: core.FromRawAmount(token0T, big.NewInt(0)),
+			ExpectedCurrencyOwed1: core.FromRawAmount(token1T, big.NewInt(0)),
+			Recipient:             recipientT,
+		},
+	})
+	assert.NoError(t, err)
+	operations, err = DecodeCalldata(params.Calldata)
+	assert.NoError(t, err)
+	assert.Equal(t, "permit", operations[0].Method)
+	assert.Equal(t, expected, operations[0].Params)
+}
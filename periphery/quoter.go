@@ -82,9 +82,12 @@ func QuoteCallParameters(
 			return nil, err
 		}
 	}
+	to := contractAddress(route.ChainID(), quoterOf)
 	return &utils.MethodParameters{
 		Calldata: calldata,
 		Value:    big.NewInt(0),
+		To:       to,
+		ChainID:  route.ChainID(),
 	}, nil
 }
 
@@ -94,9 +97,12 @@ func QuoteCallParameters(
  * @param exactOutput whether the route should be encoded in reverse, for making exact output swaps
  */
 func EncodeRouteToPath(route *entities.Route, exactOutput bool) ([]byte, error) {
-	var (
-		inputToken = route.Input.Wrapped()
+	inputToken, err := wrappedToken(route.Input)
+	if err != nil {
+		return nil, err
+	}
 
+	var (
 		types []string
 		path  []interface{}
 
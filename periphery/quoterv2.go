@@ -0,0 +1,165 @@
+package periphery
+
+import (
+	_ "embed"
+	"math/big"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/entities"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+//go:embed contracts/lens/QuoterV2.sol/QuoterV2.json
+var quoterV2ABI []byte
+
+// quotes are requested and decoded for every route that is compared, so the ABI is only parsed once
+var parsedQuoterV2ABI = GetABI(quoterV2ABI)
+
+type QuoteExactInputSingleParams struct {
+	TokenIn           common.Address
+	TokenOut          common.Address
+	AmountIn          *big.Int
+	Fee               *big.Int
+	SqrtPriceLimitX96 *big.Int
+}
+
+type QuoteExactOutputSingleParams struct {
+	TokenIn           common.Address
+	TokenOut          common.Address
+	Amount            *big.Int
+	Fee               *big.Int
+	SqrtPriceLimitX96 *big.Int
+}
+
+// The decoded result of a QuoterV2 quote
+type QuoteV2Result struct {
+	AmountOut                   *big.Int   // The output amount of an exact input quote, nil for exact output quotes
+	AmountIn                    *big.Int   // The input amount of an exact output quote, nil for exact input quotes
+	SqrtPriceX96AfterList       []*big.Int // The sqrt price of each pool of the route after the swap, in the order of the pools of the route
+	InitializedTicksCrossedList []uint32   // The number of initialized ticks crossed in each pool of the route, in the order of the pools of the route
+	GasEstimate                 *big.Int   // The estimated gas of the swap
+}
+
+// quoteV2Method returns the method of QuoterV2 that quotes the route
+func quoteV2Method(route *entities.Route, tradeType core.TradeType) string {
+	singleHop := len(route.Pools) == 1
+	switch {
+	case singleHop && tradeType == core.ExactInput:
+		return "quoteExactInputSingle"
+	case singleHop:
+		return "quoteExactOutputSingle"
+	case tradeType == core.ExactInput:
+		return "quoteExactInput"
+	default:
+		return "quoteExactOutput"
+	}
+}
+
+/**
+ * Produces the calldata of the QuoterV2 method quoting a swap along a route. Single hop routes are quoted with the
+ * struct parameter methods, multihop routes with the path ones
+ * @param route The swap route, a list of pools through which a swap can occur
+ * @param amount The amount of the quote, either an amount in, or an amount out
+ * @param tradeType The trade type, either exact input or exact output
+ * @param options The optional price limit, which only single hop quotes support
+ * @returns The call parameters
+ */
+func QuoteV2CallParameters(
+	route *entities.Route,
+	amount *core.CurrencyAmount,
+	tradeType core.TradeType,
+	options *QuoteOptions,
+) (*utils.MethodParameters, error) {
+	quoteAmount := amount.Quotient()
+	sqrtPriceLimitX96 := big.NewInt(0)
+	if options != nil && options.SqrtPriceLimitX96 != nil {
+		sqrtPriceLimitX96 = options.SqrtPriceLimitX96
+	}
+
+	var (
+		calldata []byte
+		err      error
+	)
+	method := quoteV2Method(route, tradeType)
+	switch method {
+	case "quoteExactInputSingle":
+		calldata, err = parsedQuoterV2ABI.Pack(method, &QuoteExactInputSingleParams{
+			TokenIn:           route.TokenPath[0].Address,
+			TokenOut:          route.TokenPath[1].Address,
+			AmountIn:          quoteAmount,
+			Fee:               big.NewInt(int64(route.Pools[0].Fee)),
+			SqrtPriceLimitX96: sqrtPriceLimitX96,
+		})
+	case "quoteExactOutputSingle":
+		calldata, err = parsedQuoterV2ABI.Pack(method, &QuoteExactOutputSingleParams{
+			TokenIn:           route.TokenPath[0].Address,
+			TokenOut:          route.TokenPath[1].Address,
+			Amount:            quoteAmount,
+			Fee:               big.NewInt(int64(route.Pools[0].Fee)),
+			SqrtPriceLimitX96: sqrtPriceLimitX96,
+		})
+	default:
+		if sqrtPriceLimitX96.Sign() != 0 {
+			return nil, ErrMultihopPriceLimit
+		}
+		path, err := EncodeRouteToPath(route, tradeType == core.ExactOutput)
+		if err != nil {
+			return nil, err
+		}
+		calldata, err = parsedQuoterV2ABI.Pack(method, path, quoteAmount)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	to := contractAddress(route.ChainID(), quoterV2Of)
+	return &utils.MethodParameters{
+		Calldata: calldata,
+		Value:    constants.Zero,
+		To:       to,
+		ChainID:  route.ChainID(),
+	}, nil
+}
+
+/**
+ * Decodes the result of a QuoterV2 quote produced by QuoteV2CallParameters
+ * @param route The route of the quote
+ * @param tradeType The trade type of the quote
+ * @param data The returned data
+ * @returns The decoded quote
+ */
+func DecodeQuoteV2Result(route *entities.Route, tradeType core.TradeType, data []byte) (*QuoteV2Result, error) {
+	method := quoteV2Method(route, tradeType)
+	values, err := parsedQuoterV2ABI.Unpack(method, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QuoteV2Result{GasEstimate: values[3].(*big.Int)}
+	if tradeType == core.ExactInput {
+		result.AmountOut = values[0].(*big.Int)
+	} else {
+		result.AmountIn = values[0].(*big.Int)
+	}
+
+	if len(route.Pools) == 1 {
+		result.SqrtPriceX96AfterList = []*big.Int{values[1].(*big.Int)}
+		result.InitializedTicksCrossedList = []uint32{values[2].(uint32)}
+		return result, nil
+	}
+
+	result.SqrtPriceX96AfterList = values[1].([]*big.Int)
+	result.InitializedTicksCrossedList = values[2].([]uint32)
+	if tradeType == core.ExactOutput {
+		// the path of exact output quotes is reversed
+		reverse(result.SqrtPriceX96AfterList)
+		reverse(result.InitializedTicksCrossedList)
+	}
+	return result, nil
+}
@@ -0,0 +1,85 @@
+package periphery
+
+import (
+	"math/big"
+	"testing"
+
+	core "github.com/daoleno/uniswap-sdk-core/entities"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+func TestQuoteV2CallParameters(t *testing.T) {
+	amount := core.FromRawAmount(token0, big.NewInt(100))
+
+	// single hop quotes take the struct parameters
+	params, err := QuoteV2CallParameters(route_0_1, amount, core.ExactInput, &QuoteOptions{SqrtPriceLimitX96: big.NewInt(7)})
+	assert.NoError(t, err)
+	method, err := parsedQuoterV2ABI.MethodById(params.Calldata[:4])
+	assert.NoError(t, err)
+	assert.Equal(t, "quoteExactInputSingle", method.Name)
+	values, err := method.Inputs.Unpack(params.Calldata[4:])
+	assert.NoError(t, err)
+	inputParams := abi.ConvertType(values[0], new(QuoteExactInputSingleParams))
+	assert.Equal(t, &QuoteExactInputSingleParams{
+		TokenIn:           token0.Address,
+		TokenOut:          token1.Address,
+		AmountIn:          big.NewInt(100),
+		Fee:               big.NewInt(int64(route_0_1.Pools[0].Fee)),
+		SqrtPriceLimitX96: big.NewInt(7),
+	}, inputParams)
+	assert.Equal(t, "0x00", utils.ToHex(params.Value))
+
+	params, err = QuoteV2CallParameters(route_0_1, core.FromRawAmount(token1, big.NewInt(100)), core.ExactOutput, nil)
+	assert.NoError(t, err)
+	method, err = parsedQuoterV2ABI.MethodById(params.Calldata[:4])
+	assert.NoError(t, err)
+	assert.Equal(t, "quoteExactOutputSingle", method.Name)
+
+	// multihop quotes take the path
+	params, err = QuoteV2CallParameters(route_0_1_2, core.FromRawAmount(token2, big.NewInt(100)), core.ExactOutput, nil)
+	assert.NoError(t, err)
+	method, err = parsedQuoterV2ABI.MethodById(params.Calldata[:4])
+	assert.NoError(t, err)
+	assert.Equal(t, "quoteExactOutput", method.Name)
+	values, err = method.Inputs.Unpack(params.Calldata[4:])
+	assert.NoError(t, err)
+	path, err := EncodeRouteToPath(route_0_1_2, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{path, big.NewInt(100)}, values)
+
+	_, err = QuoteV2CallParameters(route_0_1_2, amount, core.ExactInput, &QuoteOptions{SqrtPriceLimitX96: big.NewInt(7)})
+	assert.ErrorIs(t, err, ErrMultihopPriceLimit)
+}
+
+func TestDecodeQuoteV2Result(t *testing.T) {
+	data, err := parsedQuoterV2ABI.Methods["quoteExactInputSingle"].Outputs.Pack(big.NewInt(99), big.NewInt(1234), uint32(2), big.NewInt(80000))
+	assert.NoError(t, err)
+	result, err := DecodeQuoteV2Result(route_0_1, core.ExactInput, data)
+	assert.NoError(t, err)
+	assert.Equal(t, &QuoteV2Result{
+		AmountOut:                   big.NewInt(99),
+		SqrtPriceX96AfterList:       []*big.Int{big.NewInt(1234)},
+		InitializedTicksCrossedList: []uint32{2},
+		GasEstimate:                 big.NewInt(80000),
+	}, result)
+
+	// the lists of exact output quotes are returned in the order of the route
+	data, err = parsedQuoterV2ABI.Methods["quoteExactOutput"].Outputs.Pack(
+		big.NewInt(101), []*big.Int{big.NewInt(2), big.NewInt(1)}, []uint32{4, 3}, big.NewInt(150000),
+	)
+	assert.NoError(t, err)
+	result, err = DecodeQuoteV2Result(route_0_1_2, core.ExactOutput, data)
+	assert.NoError(t, err)
+	assert.Equal(t, &QuoteV2Result{
+		AmountIn:                    big.NewInt(101),
+		SqrtPriceX96AfterList:       []*big.Int{big.NewInt(1), big.NewInt(2)},
+		InitializedTicksCrossedList: []uint32{3, 4},
+		GasEstimate:                 big.NewInt(150000),
+	}, result)
+
+	_, err = DecodeQuoteV2Result(route_0_1_2, core.ExactInput, []byte{1})
+	assert.Error(t, err)
+}
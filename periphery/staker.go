@@ -122,9 +122,13 @@ func CollectRewards(incentiveKeys []*IncentiveKey, options *ClaimOptions) (*util
 		return nil, err
 	}
 
+	chainID := incentivesChainID(incentiveKeys)
+	to := contractAddress(chainID, stakerOf)
 	return &utils.MethodParameters{
 		Calldata: multiCalldata,
 		Value:    big.NewInt(0),
+		To:       to,
+		ChainID:  chainID,
 	}, nil
 }
 
@@ -162,9 +166,13 @@ func WithdrawToken(incentiveKeys []*IncentiveKey, withdrawOptions *FullWithdrawO
 	if err != nil {
 		return nil, err
 	}
+	chainID := incentivesChainID(incentiveKeys)
+	to := contractAddress(chainID, stakerOf)
 	return &utils.MethodParameters{
 		Calldata: multiCalldata,
 		Value:    big.NewInt(0),
+		To:       to,
+		ChainID:  chainID,
 	}, nil
 }
 
@@ -235,9 +243,17 @@ func EncodeDeposit(incentiveKeys []*IncentiveKey) ([]byte, error) {
 	* @returns An encoded IncentiveKey to be read by ethers
 	*
 */
+// incentivesChainID returns the chain of the pools of the incentives, zero if there are none
+func incentivesChainID(incentiveKeys []*IncentiveKey) uint {
+	if len(incentiveKeys) == 0 {
+		return 0
+	}
+	return incentiveKeys[0].Pool.ChainID()
+}
+
 func encodeIncentiveKey(incentiveKey *IncentiveKey) (*IncentiveKeyParams, error) {
 	pool := incentiveKey.Pool
-	addr, err := entities.GetAddress(pool.Token0, pool.Token1, pool.Fee, "")
+	addr, err := pool.GetAddress()
 	if err != nil {
 		return nil, err
 	}
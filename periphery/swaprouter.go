@@ -80,15 +80,29 @@ type ExactOutputParams struct {
 func SwapCallParameters(trades []*entities.Trade, options *SwapOptions) (*utils.MethodParameters, error) {
 	abi := GetABI(swapRouterABI)
 	sampleTrade := trades[0]
-	tokenIn := sampleTrade.InputAmount().Currency.Wrapped()
-	tokenOut := sampleTrade.OutputAmount().Currency.Wrapped()
+	tokenIn, err := wrappedToken(sampleTrade.InputAmount().Currency)
+	if err != nil {
+		return nil, err
+	}
+	tokenOut, err := wrappedToken(sampleTrade.OutputAmount().Currency)
+	if err != nil {
+		return nil, err
+	}
 
 	// All trades should have the same starting and ending token.
 	for _, trade := range trades {
-		if !trade.InputAmount().Currency.Wrapped().Equal(tokenIn) {
+		tradeTokenIn, err := wrappedToken(trade.InputAmount().Currency)
+		if err != nil {
+			return nil, err
+		}
+		if !tradeTokenIn.Equal(tokenIn) {
 			return nil, ErrTokenInDiff
 		}
-		if !trade.OutputAmount().Currency.Wrapped().Equal(tokenOut) {
+		tradeTokenOut, err := wrappedToken(trade.OutputAmount().Currency)
+		if err != nil {
+			return nil, err
+		}
+		if !tradeTokenOut.Equal(tokenOut) {
 			return nil, ErrTokenOutDiff
 		}
 	}
@@ -271,8 +285,11 @@ func SwapCallParameters(trades []*entities.Trade, options *SwapOptions) (*utils.
 	if err != nil {
 		return nil, err
 	}
+	to := contractAddress(sampleTrade.InputAmount().Currency.ChainId(), routerOf)
 	return &utils.MethodParameters{
 		Calldata: call,
 		Value:    totalValue.Quotient(),
+		To:       to,
+		ChainID:  sampleTrade.InputAmount().Currency.ChainId(),
 	}, nil
 }
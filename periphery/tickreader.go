@@ -0,0 +1,349 @@
+package periphery
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/promm-sdk-go/abis"
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/entities"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+var (
+	ErrPoolTickCallFailed = errors.New("pool tick call failed")
+)
+
+//go:embed contracts/interfaces/ITickReader.sol/ITickReader.json
+var tickReaderABI []byte
+
+//go:embed contracts/lens/UniswapInterfaceMulticall.sol/UniswapInterfaceMulticall.json
+var interfaceMulticallABI []byte
+
+// the ticks of a pool are loaded range by range, so the ABIs are only parsed once
+var (
+	parsedTickReaderABI         = GetABI(tickReaderABI)
+	parsedInterfaceMulticallABI = GetABI(interfaceMulticallABI)
+)
+
+// checkTick returns utils.ErrInvalidTick for ticks that do not fit the int24 ticks of the pools
+func checkTick(tick int) error {
+	if tick < utils.MinTick || tick > utils.MaxTick {
+		return utils.ErrInvalidTick
+	}
+	return nil
+}
+
+// tickReaderCallParameters packs a call to the tick reader of the deployment of a chain
+func tickReaderCallParameters(chainID uint, method string, args ...interface{}) (*utils.MethodParameters, error) {
+	calldata, err := parsedTickReaderABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	to := contractAddress(chainID, tickReaderOf)
+	return &utils.MethodParameters{
+		Calldata: calldata,
+		Value:    constants.Zero,
+		To:       to,
+		ChainID:  chainID,
+	}, nil
+}
+
+/**
+ * Produces the calldata for getting the initialized ticks of a pool nearest to a tick with the tick reader
+ * @param pool The address of the pool
+ * @param tick The tick
+ * @param chainID The chain ID of the pool
+ * @returns The call parameters, utils.ErrInvalidTick if the tick is out of range
+ */
+func NearestInitializedTicksCallParameters(pool common.Address, tick int, chainID uint) (*utils.MethodParameters, error) {
+	if err := checkTick(tick); err != nil {
+		return nil, err
+	}
+	return tickReaderCallParameters(chainID, "getNearestInitializedTicks", pool, big.NewInt(int64(tick)))
+}
+
+/**
+ * Decodes the result of getNearestInitializedTicks
+ * @param data The returned data
+ * @returns The initialized ticks at or below, and above the tick
+ */
+func DecodeNearestInitializedTicks(data []byte) (int, int, error) {
+	values, err := parsedTickReaderABI.Unpack("getNearestInitializedTicks", data)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(values[0].(*big.Int).Int64()), int(values[1].(*big.Int).Int64()), nil
+}
+
+/**
+ * Produces the calldata for getting the initialized ticks of a pool from an initialized tick with the tick reader
+ * @param pool The address of the pool
+ * @param startTick The initialized tick the ticks are listed from, the tick reader reverts for other ticks
+ * @param length The maximum number of ticks returned
+ * @param chainID The chain ID of the pool
+ * @returns The call parameters, utils.ErrInvalidTick if the tick is out of range
+ */
+func TicksInRangeCallParameters(pool common.Address, startTick int, length uint32, chainID uint) (*utils.MethodParameters, error) {
+	if err := checkTick(startTick); err != nil {
+		return nil, err
+	}
+	return tickReaderCallParameters(chainID, "getTicksInRange", pool, big.NewInt(int64(startTick)), length)
+}
+
+/**
+ * Decodes the result of getTicksInRange
+ * @param data The returned data
+ * @returns The indexes of the initialized ticks in ascending order, the ones after utils.MaxTick are dropped as the
+ * tick reader pads the list with zeros when it reaches the end of the linked list of the pool
+ */
+func DecodeTickIndexesInRange(data []byte) ([]int, error) {
+	values, err := parsedTickReaderABI.Unpack("getTicksInRange", data)
+	if err != nil {
+		return nil, err
+	}
+	allTicks := values[0].([]*big.Int)
+
+	indexes := make([]int, 0, len(allTicks))
+	for _, tick := range allTicks {
+		indexes = append(indexes, int(tick.Int64()))
+		if tick.Int64() == utils.MaxTick {
+			break
+		}
+	}
+	return indexes, nil
+}
+
+/**
+ * Produces the calldata for getting the liquidity of a tick of a pool from the pool itself
+ * @param pool The address of the pool
+ * @param tick The tick
+ * @param chainID The chain ID of the pool
+ * @returns The call parameters, utils.ErrInvalidTick if the tick is out of range
+ */
+func PoolTickCallParameters(pool common.Address, tick int, chainID uint) (*utils.MethodParameters, error) {
+	if err := checkTick(tick); err != nil {
+		return nil, err
+	}
+	calldata, err := abis.PoolABI.Pack("ticks", big.NewInt(int64(tick)))
+	if err != nil {
+		return nil, err
+	}
+	return &utils.MethodParameters{
+		Calldata: calldata,
+		Value:    constants.Zero,
+		To:       pool,
+		ChainID:  chainID,
+	}, nil
+}
+
+/**
+ * Decodes the result of the ticks function of a pool
+ * @param tick The tick the data was returned for
+ * @param data The returned data
+ * @returns The tick, ready for NewTickListDataProvider
+ */
+func DecodePoolTick(tick int, data []byte) (entities.Tick, error) {
+	values, err := abis.PoolABI.Unpack("ticks", data)
+	if err != nil {
+		return entities.Tick{}, err
+	}
+	return entities.Tick{
+		Index:                      tick,
+		LiquidityGross:             values[0].(*big.Int),
+		LiquidityNet:               values[1].(*big.Int),
+		FeeGrowthOutside:           values[2].(*big.Int),
+		SecondsPerLiquidityOutside: values[3].(*big.Int),
+	}, nil
+}
+
+// multicallCall is a call of the multicall function of UniswapInterfaceMulticall
+type multicallCall struct {
+	Target   common.Address
+	GasLimit *big.Int
+	CallData []byte
+}
+
+// multicallResult is a result of the multicall function of UniswapInterfaceMulticall
+type multicallResult struct {
+	Success    bool
+	GasUsed    *big.Int
+	ReturnData []byte
+}
+
+// poolTickGasLimit is the gas each ticks call of a batch may use, reading a tick takes a few storage loads
+var poolTickGasLimit = big.NewInt(100000)
+
+/**
+ * Produces the calldata for getting the ticks of a pool in a single call, batched with the multicall contract of the
+ * deployment of the chain of the pool
+ * @param pool The address of the pool
+ * @param ticks The indexes of the ticks, e.g. the ones returned by getTicksInRange
+ * @param chainID The chain ID of the pool
+ * @returns The call parameters, utils.ErrInvalidTick if a tick is out of range
+ */
+func PoolTicksCallParameters(pool common.Address, ticks []int, chainID uint) (*utils.MethodParameters, error) {
+	calls := make([]multicallCall, 0, len(ticks))
+	for _, tick := range ticks {
+		params, err := PoolTickCallParameters(pool, tick, chainID)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, multicallCall{Target: pool, GasLimit: poolTickGasLimit, CallData: params.Calldata})
+	}
+	calldata, err := parsedInterfaceMulticallABI.Pack("multicall", calls)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.MethodParameters{
+		Calldata: calldata,
+		Value:    constants.Zero,
+		To:       contractAddress(chainID, multicallOf),
+		ChainID:  chainID,
+	}, nil
+}
+
+/**
+ * Decodes the result of the call of PoolTicksCallParameters
+ * @param ticks The indexes of the ticks the call was made for
+ * @param data The returned data
+ * @returns The ticks, ready for NewTickListDataProvider, ErrPoolTickCallFailed if the call of a tick failed
+ */
+func DecodePoolTicks(ticks []int, data []byte) ([]entities.Tick, error) {
+	values, err := parsedInterfaceMulticallABI.Unpack("multicall", data)
+	if err != nil {
+		return nil, err
+	}
+	results := *abi.ConvertType(values[1], new([]multicallResult)).(*[]multicallResult)
+	if len(results) != len(ticks) {
+		return nil, ErrPoolTickCallFailed
+	}
+
+	decoded := make([]entities.Tick, 0, len(ticks))
+	for i, result := range results {
+		if !result.Success {
+			return nil, ErrPoolTickCallFailed
+		}
+		tick, err := DecodePoolTick(ticks[i], result.ReturnData)
+		if err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, tick)
+	}
+	return decoded, nil
+}
+
+/**
+ * TickReaderFetcher is an entities.TickFetcher loading the initialized ticks of a pool with the tick reader of the
+ * deployment of its chain. A fetch lists up to length initialized ticks from the one at or below the fetched tick,
+ * then reads them from the pool in a single call batched with the multicall contract of the deployment
+ */
+type TickReaderFetcher struct {
+	ctx         context.Context
+	caller      ethereum.ContractCaller
+	pool        common.Address
+	chainID     uint
+	length      uint32
+	blockNumber *big.Int
+}
+
+/**
+ * Constructs a tick reader fetcher
+ * @param ctx The context of the calls
+ * @param caller The client the calls are made with
+ * @param pool The address of the pool
+ * @param chainID The chain ID of the pool, its deployment must know the tick reader and the multicall contract
+ * @param length The maximum number of ticks listed by a fetch, at least 3 so that the range around an initialized
+ * tick contains it
+ * @param blockNumber The block the ticks are loaded at, nil for the latest block
+ * @returns The fetcher, ErrInvalidOptions if the length is too small, constants.ErrUnknownContract if the tick reader
+ * or the multicall contract of the chain is not known
+ */
+func NewTickReaderFetcher(
+	ctx context.Context, caller ethereum.ContractCaller, pool common.Address, chainID uint, length uint32, blockNumber *big.Int,
+) (*TickReaderFetcher, error) {
+	if length < 3 {
+		return nil, ErrInvalidOptions
+	}
+	if contractAddress(chainID, tickReaderOf) == (common.Address{}) || contractAddress(chainID, multicallOf) == (common.Address{}) {
+		return nil, constants.ErrUnknownContract
+	}
+	return &TickReaderFetcher{
+		ctx:         ctx,
+		caller:      caller,
+		pool:        pool,
+		chainID:     chainID,
+		length:      length,
+		blockNumber: blockNumber,
+	}, nil
+}
+
+// call makes the call of the method parameters at the block of the fetcher
+func (f *TickReaderFetcher) call(params *utils.MethodParameters) ([]byte, error) {
+	return f.caller.CallContract(f.ctx, ethereum.CallMsg{To: &params.To, Data: params.Calldata}, f.blockNumber)
+}
+
+// FetchTicks returns the initialized ticks from the one at or below the tick, the range ends at the last listed tick
+// unless the list reached utils.MaxTick. The bounds of the linked list of the pool, utils.MinTick and utils.MaxTick,
+// hold no liquidity and are not returned. A fetch makes three calls whatever the number of ticks
+func (f *TickReaderFetcher) FetchTicks(tick int) ([]entities.Tick, int, int, error) {
+	params, err := NearestInitializedTicksCallParameters(f.pool, tick, f.chainID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	data, err := f.call(params)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	previous, _, err := DecodeNearestInitializedTicks(data)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	params, err = TicksInRangeCallParameters(f.pool, previous, f.length, f.chainID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	data, err = f.call(params)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	indexes, err := DecodeTickIndexesInRange(data)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	tickUpper := utils.MaxTick
+	if len(indexes) == int(f.length) && indexes[len(indexes)-1] != utils.MaxTick {
+		tickUpper = indexes[len(indexes)-1]
+	}
+	initialized := make([]int, 0, len(indexes))
+	for _, index := range indexes {
+		if index != utils.MinTick && index != utils.MaxTick {
+			initialized = append(initialized, index)
+		}
+	}
+	if len(initialized) == 0 {
+		return nil, previous, tickUpper, nil
+	}
+
+	params, err = PoolTicksCallParameters(f.pool, initialized, f.chainID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	data, err = f.call(params)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	ticks, err := DecodePoolTicks(initialized, data)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return ticks, previous, tickUpper, nil
+}
@@ -0,0 +1,280 @@
+package periphery
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/promm-sdk-go/abis"
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+	"github.com/KyberNetwork/promm-sdk-go/entities"
+	"github.com/KyberNetwork/promm-sdk-go/utils"
+)
+
+// the tick reader is not known on the built-in chains, the tests register it on a chain of their own
+const tickReaderChainID = 9997
+
+var (
+	testTickReader = common.HexToAddress("0x0000000000000000000000000000000000000016")
+	testMulticall  = common.HexToAddress("0x0000000000000000000000000000000000000018")
+)
+
+func init() {
+	constants.Deployments.Register(tickReaderChainID, &constants.Deployment{TickReader: testTickReader, Multicall: testMulticall})
+}
+
+// tickReaderCaller answers the tick reader and the multicall of pool calls from memory, the linked list of the pool
+// holds the ticks between utils.MinTick and utils.MaxTick, and getTicksInRange pads its result with zeros like the tick
+// reader
+type tickReaderCaller struct {
+	pool  common.Address
+	ticks []entities.Tick
+	calls []ethereum.CallMsg
+}
+
+func (c *tickReaderCaller) linkedList() []int {
+	list := []int{utils.MinTick}
+	for _, tick := range c.ticks {
+		list = append(list, tick.Index)
+	}
+	return append(list, utils.MaxTick)
+}
+
+// poolTick answers the ticks call of the pool
+func (c *tickReaderCaller) poolTick(data []byte) ([]byte, error) {
+	method := abis.PoolABI.Methods["ticks"]
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	for _, tick := range c.ticks {
+		if int64(tick.Index) == values[0].(*big.Int).Int64() {
+			return method.Outputs.Pack(tick.LiquidityGross, tick.LiquidityNet, tick.FeeGrowthOutside, tick.SecondsPerLiquidityOutside)
+		}
+	}
+	return method.Outputs.Pack(big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0))
+}
+
+func (c *tickReaderCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.calls = append(c.calls, call)
+	if *call.To == testMulticall {
+		method := parsedInterfaceMulticallABI.Methods["multicall"]
+		values, err := method.Inputs.Unpack(call.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+		calls := *abi.ConvertType(values[0], new([]multicallCall)).(*[]multicallCall)
+		results := make([]multicallResult, 0, len(calls))
+		for _, call := range calls {
+			if call.Target != c.pool {
+				return nil, errors.New("unexpected call")
+			}
+			data, err := c.poolTick(call.CallData)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, multicallResult{Success: true, GasUsed: big.NewInt(0), ReturnData: data})
+		}
+		return method.Outputs.Pack(big.NewInt(1), results)
+	}
+	if *call.To != testTickReader {
+		return nil, errors.New("unexpected call")
+	}
+
+	method, err := parsedTickReaderABI.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	values, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	list := c.linkedList()
+	tick := int(values[1].(*big.Int).Int64())
+	switch method.Name {
+	case "getNearestInitializedTicks":
+		i := sort.SearchInts(list, tick+1)
+		return method.Outputs.Pack(big.NewInt(int64(list[i-1])), big.NewInt(int64(list[i])))
+	default:
+		i := sort.SearchInts(list, tick)
+		if list[i] != tick {
+			return nil, errors.New("startTick not initialized")
+		}
+		allTicks := make([]*big.Int, values[2].(uint32))
+		for j := range allTicks {
+			allTicks[j] = big.NewInt(0)
+			if i+j < len(list) {
+				allTicks[j] = big.NewInt(int64(list[i+j]))
+			}
+		}
+		return method.Outputs.Pack(allTicks)
+	}
+}
+
+func TestTickReaderCallParameters(t *testing.T) {
+	pool := common.HexToAddress("0x0000000000000000000000000000000000000005")
+
+	params, err := NearestInitializedTicksCallParameters(pool, -60, tickReaderChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, testTickReader, params.To)
+	assert.Equal(t, uint(tickReaderChainID), params.ChainID)
+	assert.Equal(t, big.NewInt(0), params.Value)
+	method, err := parsedTickReaderABI.MethodById(params.Calldata[:4])
+	assert.NoError(t, err)
+	assert.Equal(t, "getNearestInitializedTicks", method.Name)
+	values, err := method.Inputs.Unpack(params.Calldata[4:])
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{pool, big.NewInt(-60)}, values)
+
+	params, err = TicksInRangeCallParameters(pool, utils.MinTick, 10, tickReaderChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, testTickReader, params.To)
+	method, err = parsedTickReaderABI.MethodById(params.Calldata[:4])
+	assert.NoError(t, err)
+	assert.Equal(t, "getTicksInRange", method.Name)
+	values, err = method.Inputs.Unpack(params.Calldata[4:])
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{pool, big.NewInt(utils.MinTick), uint32(10)}, values)
+
+	params, err = PoolTickCallParameters(pool, 120, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, pool, params.To)
+	assert.Equal(t, uint(1), params.ChainID)
+
+	// the ticks are read in a single call through the multicall contract
+	params, err = PoolTicksCallParameters(pool, []int{-60, 120}, tickReaderChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, testMulticall, params.To)
+	values, err = parsedInterfaceMulticallABI.Methods["multicall"].Inputs.Unpack(params.Calldata[4:])
+	assert.NoError(t, err)
+	calls := *abi.ConvertType(values[0], new([]multicallCall)).(*[]multicallCall)
+	assert.Len(t, calls, 2)
+	assert.Equal(t, pool, calls[1].Target)
+	tickParams, err := PoolTickCallParameters(pool, 120, tickReaderChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, tickParams.Calldata, calls[1].CallData)
+
+	// ticks beyond the int24 ticks of the pools are rejected instead of being truncated
+	_, err = NearestInitializedTicksCallParameters(pool, utils.MaxTick+1, 1)
+	assert.ErrorIs(t, err, utils.ErrInvalidTick)
+	_, err = TicksInRangeCallParameters(pool, utils.MinTick-1, 10, 1)
+	assert.ErrorIs(t, err, utils.ErrInvalidTick)
+	_, err = PoolTickCallParameters(pool, 1<<23, 1)
+	assert.ErrorIs(t, err, utils.ErrInvalidTick)
+	_, err = PoolTicksCallParameters(pool, []int{0, 1 << 23}, 1)
+	assert.ErrorIs(t, err, utils.ErrInvalidTick)
+
+	// the target is left to the caller on the chains where the tick reader is not known
+	params, err = NearestInitializedTicksCallParameters(pool, 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Address{}, params.To)
+}
+
+func TestDecodeTickReader(t *testing.T) {
+	data, err := parsedTickReaderABI.Methods["getNearestInitializedTicks"].Outputs.Pack(big.NewInt(-60), big.NewInt(120))
+	assert.NoError(t, err)
+	previous, next, err := DecodeNearestInitializedTicks(data)
+	assert.NoError(t, err)
+	assert.Equal(t, -60, previous)
+	assert.Equal(t, 120, next)
+
+	data, err = parsedTickReaderABI.Methods["getTicksInRange"].Outputs.Pack([]*big.Int{
+		big.NewInt(-60), big.NewInt(120), big.NewInt(utils.MaxTick), big.NewInt(0), big.NewInt(0),
+	})
+	assert.NoError(t, err)
+	indexes, err := DecodeTickIndexesInRange(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{-60, 120, utils.MaxTick}, indexes, "drops the padding")
+
+	data, err = abis.PoolABI.Methods["ticks"].Outputs.Pack(big.NewInt(5), big.NewInt(-5), big.NewInt(1), big.NewInt(2))
+	assert.NoError(t, err)
+	tick, err := DecodePoolTick(120, data)
+	assert.NoError(t, err)
+	expected := entities.Tick{
+		Index: 120, LiquidityGross: big.NewInt(5), LiquidityNet: big.NewInt(-5),
+		FeeGrowthOutside: big.NewInt(1), SecondsPerLiquidityOutside: big.NewInt(2),
+	}
+	assert.Equal(t, expected, tick)
+
+	method := parsedInterfaceMulticallABI.Methods["multicall"]
+	data, err = method.Outputs.Pack(big.NewInt(1), []multicallResult{{Success: true, GasUsed: big.NewInt(0), ReturnData: data}})
+	assert.NoError(t, err)
+	ticks, err := DecodePoolTicks([]int{120}, data)
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.Tick{expected}, ticks)
+	_, err = DecodePoolTicks([]int{120, 180}, data)
+	assert.ErrorIs(t, err, ErrPoolTickCallFailed)
+
+	data, err = method.Outputs.Pack(big.NewInt(1), []multicallResult{{Success: false, GasUsed: big.NewInt(0), ReturnData: []byte{}}})
+	assert.NoError(t, err)
+	_, err = DecodePoolTicks([]int{120}, data)
+	assert.ErrorIs(t, err, ErrPoolTickCallFailed)
+
+	_, err = DecodeTickIndexesInRange([]byte{1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestTickReaderFetcher(t *testing.T) {
+	ticks := []entities.Tick{
+		{Index: -256 * 60 * 3, LiquidityNet: big.NewInt(5), LiquidityGross: big.NewInt(5)},
+		{Index: -60, LiquidityNet: big.NewInt(5), LiquidityGross: big.NewInt(5)},
+		{Index: 120, LiquidityNet: big.NewInt(-5), LiquidityGross: big.NewInt(5)},
+		{Index: 256 * 60 * 2, LiquidityNet: big.NewInt(-5), LiquidityGross: big.NewInt(5)},
+	}
+	for i := range ticks {
+		ticks[i].FeeGrowthOutside = big.NewInt(int64(i + 1))
+		ticks[i].SecondsPerLiquidityOutside = big.NewInt(int64(i + 10))
+	}
+	pool := common.HexToAddress("0x0000000000000000000000000000000000000005")
+	caller := &tickReaderCaller{pool: pool, ticks: ticks}
+
+	_, err := NewTickReaderFetcher(context.Background(), caller, pool, tickReaderChainID, 2, nil)
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	_, err = NewTickReaderFetcher(context.Background(), caller, pool, 1, 3, nil)
+	assert.ErrorIs(t, err, constants.ErrUnknownContract)
+	constants.Deployments.Register(9996, &constants.Deployment{TickReader: testTickReader})
+	_, err = NewTickReaderFetcher(context.Background(), caller, pool, 9996, 3, nil)
+	assert.ErrorIs(t, err, constants.ErrUnknownContract, "the multicall contract is required")
+	fetcher, err := NewTickReaderFetcher(context.Background(), caller, pool, tickReaderChainID, 3, nil)
+	assert.NoError(t, err)
+
+	fetched, tickLower, tickUpper, err := fetcher.FetchTicks(-1)
+	assert.NoError(t, err)
+	assert.Equal(t, ticks[1:4], fetched)
+	assert.Equal(t, -60, tickLower)
+	assert.Equal(t, 256*60*2, tickUpper)
+	assert.Len(t, caller.calls, 3, "the ticks are read in a single call")
+	assert.Equal(t, testTickReader, *caller.calls[0].To)
+	assert.Equal(t, testMulticall, *caller.calls[2].To)
+
+	// the list reached the end of the linked list, the range covers the ticks up to utils.MaxTick
+	fetched, tickLower, tickUpper, err = fetcher.FetchTicks(256 * 60 * 2)
+	assert.NoError(t, err)
+	assert.Equal(t, ticks[3:], fetched)
+	assert.Equal(t, 256*60*2, tickLower)
+	assert.Equal(t, utils.MaxTick, tickUpper)
+
+	// the lazy provider loads the ranges it walks through
+	provider, err := entities.NewLazyTickDataProvider(fetcher, 60)
+	assert.NoError(t, err)
+	next, initialized, err := provider.NextInitializedTickWithinFixedDistance(0, false, 480)
+	assert.NoError(t, err)
+	assert.Equal(t, 120, next)
+	assert.True(t, initialized)
+	tick, err := provider.GetTick(-60)
+	assert.NoError(t, err)
+	assert.Equal(t, ticks[1], tick)
+	tick, err = provider.GetTick(-61)
+	assert.NoError(t, err)
+	assert.Equal(t, ticks[0], tick)
+	// the range up to utils.MaxTick proves there is no initialized tick above the last one
+	_, _, err = provider.NextInitializedTickWithinOneWord(256*60*2, false, 60)
+	assert.ErrorIs(t, err, entities.ErrAtOrAboveLargest)
+}
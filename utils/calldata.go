@@ -2,11 +2,15 @@ package utils
 
 import (
 	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type MethodParameters struct {
-	Calldata []byte   // The hex encoded calldata to perform the given operation
-	Value    *big.Int // The amount of ether (wei) to send in hex
+	Calldata []byte         // The hex encoded calldata to perform the given operation
+	Value    *big.Int       // The amount of ether (wei) to send in hex
+	To       common.Address // The contract to call, zero if the encoder cannot tell which one
+	ChainID  uint           // The chain of the contract, zero if the encoder cannot tell which one
 }
 
 /**
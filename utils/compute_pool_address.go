@@ -17,6 +17,7 @@ import (
  * @param tokenA The first token of the pair, irrespective of sort order
  * @param tokenB The second token of the pair, irrespective of sort order
  * @param fee The fee tier of the pool
+ * @param initCodeHashManualOverride The init code hash of the pools of the factory, empty for constants.PoolInitCodeHash
  * @returns The pool address
  */
 func ComputePoolAddress(factoryAddress common.Address, tokenA *entities.Token, tokenB *entities.Token, fee constants.FeeAmount, initCodeHashManualOverride string) (common.Address, error) {
@@ -43,7 +44,7 @@ func getCreate2Address(factoyAddress, addressA, addressB common.Address, fee con
 	copy(salt[:], crypto.Keccak256(abiEncode(addressA, addressB, fee)))
 
 	if initCodeHashManualOverride != "" {
-		crypto.CreateAddress2(factoyAddress, salt, common.FromHex(initCodeHashManualOverride))
+		return crypto.CreateAddress2(factoyAddress, salt, common.FromHex(initCodeHashManualOverride))
 	}
 	return crypto.CreateAddress2(factoyAddress, salt, common.FromHex(constants.PoolInitCodeHash))
 }
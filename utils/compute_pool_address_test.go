@@ -32,3 +32,17 @@ func TestComputePoolAddress(t *testing.T) {
 	}
 	assert.Equal(t, resultA, resultB, "should correctly compute the pool address")
 }
+
+func TestComputePoolAddress_InitCodeHashManualOverride(t *testing.T) {
+	factoryAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	USDC := entities.NewToken(1, common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"), 18, "USDC", "USD Coin")
+	DAI := entities.NewToken(1, common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F"), 18, "DAI", "Dai Stablecoin")
+
+	result, err := ComputePoolAddress(factoryAddress, USDC, DAI, constants.Fee001, constants.PoolInitCodeHash)
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress("0xE6843dD76b942866E5d14104BE14dc069b0B4D36"), result, "same as without override")
+
+	result, err = ComputePoolAddress(factoryAddress, USDC, DAI, constants.Fee001, "0xc597aba1bb02db42ba24a8878837965718c032f8b46be94a6e46452a9f89ca01")
+	assert.NoError(t, err)
+	assert.NotEqual(t, common.HexToAddress("0xE6843dD76b942866E5d14104BE14dc069b0B4D36"), result, "uses the override")
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"math/big"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+)
+
+// GetQty0FromBurnRTokens calculates the amount of token0 returned when burning reinvestment tokens
+// worth liquidity of the reinvestment curve, at the sqrt price sqrtP
+// qty0 = liquidity / sqrtP
+func GetQty0FromBurnRTokens(sqrtP, liquidity *big.Int) *big.Int {
+	return MulDivRoundingDown(liquidity, constants.Q96, sqrtP)
+}
+
+// GetQty1FromBurnRTokens calculates the amount of token1 returned when burning reinvestment tokens
+// worth liquidity of the reinvestment curve, at the sqrt price sqrtP
+// qty1 = liquidity * sqrtP
+func GetQty1FromBurnRTokens(sqrtP, liquidity *big.Int) *big.Int {
+	return MulDivRoundingDown(liquidity, sqrtP, constants.Q96)
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"math/big"
+
+	"github.com/KyberNetwork/promm-sdk-go/constants"
+)
+
+// CalcRMintQty calculates the amount of reinvestment tokens to be minted to LPs
+// for the growth of reinvestL since reinvestLLast
+// rMintQty = rTotalSupply * lpContribution / reinvestLLast
+// where lpContribution = baseL * (reinvestL - reinvestLLast) / (baseL + reinvestL)
+func CalcRMintQty(reinvestL, reinvestLLast, baseL, rTotalSupply *big.Int) *big.Int {
+	if reinvestLLast.Cmp(constants.Zero) == 0 {
+		return big.NewInt(0)
+	}
+	lpContribution := MulDivRoundingDown(
+		baseL, new(big.Int).Sub(reinvestL, reinvestLLast), new(big.Int).Add(baseL, reinvestL),
+	)
+	return MulDivRoundingDown(rTotalSupply, lpContribution, reinvestLLast)
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrNoTarget        = errors.New("no target contract")
+	ErrNoChainID       = errors.New("no chain id")
+	ErrNoFees          = errors.New("no fees")
+	ErrMixedFees       = errors.New("both gas price and fee caps")
+	ErrSignerMismatch  = errors.New("signer does not match the sender")
+	ErrChainIDMismatch = errors.New("chain id does not match the method parameters")
+)
+
+// TransactionSigner signs a transaction sent from an account, the Signer of a go-ethereum bind.TransactOpts can be
+// converted to it
+type TransactionSigner func(from common.Address, tx *types.Transaction) (*types.Transaction, error)
+
+/**
+ * Constructs a signer of the transactions of a private key
+ * @param key The private key
+ * @param chainID The chain ID the transactions are signed for
+ */
+func NewKeyedTransactionSigner(key *ecdsa.PrivateKey, chainID *big.Int) TransactionSigner {
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(chainID)
+	return func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if from != address {
+			return nil, ErrSignerMismatch
+		}
+		return types.SignTx(tx, signer, key)
+	}
+}
+
+// The transaction inputs that do not depend on the call
+type TransactionOptions struct {
+	Nonce     uint64
+	Gas       uint64   // The gas limit
+	GasPrice  *big.Int // The gas price of a legacy transaction, nil for a dynamic fee transaction
+	GasTipCap *big.Int // The max priority fee per gas of a dynamic fee transaction
+	GasFeeCap *big.Int // The max fee per gas of a dynamic fee transaction
+}
+
+/**
+ * Builds the unsigned transaction of the call, a legacy transaction if the options have a gas price, a dynamic fee
+ * transaction otherwise
+ * @param opts The nonce, gas and fees of the transaction
+ * @returns The transaction
+ */
+func (p *MethodParameters) NewTransaction(opts *TransactionOptions) (*types.Transaction, error) {
+	if p.To == (common.Address{}) {
+		return nil, ErrNoTarget
+	}
+	value := new(big.Int)
+	if p.Value != nil {
+		value.Set(p.Value)
+	}
+
+	if opts.GasPrice != nil {
+		if opts.GasTipCap != nil || opts.GasFeeCap != nil {
+			return nil, ErrMixedFees
+		}
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    opts.Nonce,
+			GasPrice: opts.GasPrice,
+			Gas:      opts.Gas,
+			To:       &p.To,
+			Value:    value,
+			Data:     p.Calldata,
+		}), nil
+	}
+
+	if opts.GasTipCap == nil || opts.GasFeeCap == nil {
+		return nil, ErrNoFees
+	}
+	if p.ChainID == 0 {
+		return nil, ErrNoChainID
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(uint64(p.ChainID)),
+		Nonce:     opts.Nonce,
+		GasTipCap: opts.GasTipCap,
+		GasFeeCap: opts.GasFeeCap,
+		Gas:       opts.Gas,
+		To:        &p.To,
+		Value:     value,
+		Data:      p.Calldata,
+	}), nil
+}
+
+/**
+ * Builds and signs the transaction of the call
+ * @param opts The nonce, gas and fees of the transaction
+ * @param from The account the transaction is sent from
+ * @param signer The signer of the account, e.g. NewKeyedTransactionSigner
+ * @returns The signed transaction
+ */
+func (p *MethodParameters) SignTransaction(opts *TransactionOptions, from common.Address, signer TransactionSigner) (*types.Transaction, error) {
+	tx, err := p.NewTransaction(opts)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := signer(from, tx)
+	if err != nil {
+		return nil, err
+	}
+	// a legacy transaction signed for another chain would be replayable there, and fail here
+	if p.ChainID != 0 && signed.Protected() && signed.ChainId().Uint64() != uint64(p.ChainID) {
+		return nil, ErrChainIDMismatch
+	}
+	return signed, nil
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransaction(t *testing.T) {
+	params := &MethodParameters{
+		Calldata: []byte{1, 2, 3},
+		Value:    big.NewInt(7),
+		To:       common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		ChainID:  1,
+	}
+
+	tx, err := params.NewTransaction(&TransactionOptions{Nonce: 3, Gas: 21000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(100)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+	assert.Equal(t, big.NewInt(1), tx.ChainId())
+	assert.Equal(t, uint64(3), tx.Nonce())
+	assert.Equal(t, uint64(21000), tx.Gas())
+	assert.Equal(t, big.NewInt(1), tx.GasTipCap())
+	assert.Equal(t, big.NewInt(100), tx.GasFeeCap())
+	assert.Equal(t, params.To, *tx.To())
+	assert.Equal(t, params.Value, tx.Value())
+	assert.Equal(t, params.Calldata, tx.Data())
+
+	tx, err = params.NewTransaction(&TransactionOptions{Nonce: 3, Gas: 21000, GasPrice: big.NewInt(50)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.LegacyTxType), tx.Type())
+	assert.Equal(t, big.NewInt(50), tx.GasPrice())
+
+	_, err = params.NewTransaction(&TransactionOptions{GasPrice: big.NewInt(50), GasFeeCap: big.NewInt(100)})
+	assert.ErrorIs(t, err, ErrMixedFees)
+	_, err = params.NewTransaction(&TransactionOptions{GasTipCap: big.NewInt(1)})
+	assert.ErrorIs(t, err, ErrNoFees)
+	_, err = (&MethodParameters{To: params.To}).NewTransaction(&TransactionOptions{GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(100)})
+	assert.ErrorIs(t, err, ErrNoChainID)
+	_, err = (&MethodParameters{ChainID: 1}).NewTransaction(&TransactionOptions{GasPrice: big.NewInt(50)})
+	assert.ErrorIs(t, err, ErrNoTarget)
+}
+
+func TestSignTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	params := &MethodParameters{
+		Calldata: []byte{1, 2, 3},
+		Value:    big.NewInt(7),
+		To:       common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		ChainID:  137,
+	}
+	signer := NewKeyedTransactionSigner(key, big.NewInt(137))
+
+	for _, opts := range []*TransactionOptions{
+		{Nonce: 1, Gas: 100000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(100)},
+		{Nonce: 1, Gas: 100000, GasPrice: big.NewInt(50)},
+	} {
+		tx, err := params.SignTransaction(opts, from, signer)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(137), tx.ChainId())
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		assert.NoError(t, err)
+		assert.Equal(t, from, sender)
+	}
+
+	_, err = params.SignTransaction(&TransactionOptions{GasPrice: big.NewInt(50)}, params.To, signer)
+	assert.ErrorIs(t, err, ErrSignerMismatch)
+	_, err = params.SignTransaction(&TransactionOptions{GasPrice: big.NewInt(50)}, from, NewKeyedTransactionSigner(key, big.NewInt(1)))
+	assert.ErrorIs(t, err, ErrChainIDMismatch)
+	_, err = params.SignTransaction(&TransactionOptions{GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(100)}, from, NewKeyedTransactionSigner(key, big.NewInt(1)))
+	assert.Error(t, err)
+}